@@ -0,0 +1,176 @@
+package azure
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"code.gitea.io/sdk/gitea"
+	"github.com/ItsNotGoodName/sync-gitea-mirrors/tea"
+)
+
+const APIVersion = "7.0"
+
+// GitUsername is used for git authentication, Azure DevOps ignores the username when the password is a PAT.
+const GitUsername = "pat"
+
+type Project struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Visibility  string `json:"visibility"`
+}
+
+type Repository struct {
+	ID            string  `json:"id"`
+	Name          string  `json:"name"`
+	Project       Project `json:"project"`
+	DefaultBranch string  `json:"defaultBranch"`
+	Size          int64   `json:"size"`
+	RemoteURL     string  `json:"remoteUrl"`
+	WebURL        string  `json:"webUrl"`
+	IsFork        bool    `json:"isFork"`
+	IsDisabled    bool    `json:"isDisabled"`
+}
+
+type Client struct {
+	// URL is the URL of the organization (e.g. https://dev.azure.com/org) or the collection of Azure DevOps Server.
+	URL   string
+	Token string
+	HTTP  *http.Client
+}
+
+func NewClient(baseURL, org, token string) *Client {
+	return &Client{
+		URL:   strings.TrimSuffix(baseURL, "/") + "/" + url.PathEscape(org),
+		Token: token,
+		HTTP:  http.DefaultClient,
+	}
+}
+
+func (c *Client) get(path string, v any) error {
+	req, err := http.NewRequest("GET", c.URL+path, nil)
+	if err != nil {
+		return err
+	}
+	req.SetBasicAuth("", c.Token)
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.HTTP.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s: %s", path, resp.Status)
+	}
+
+	return json.NewDecoder(resp.Body).Decode(v)
+}
+
+// ListRepos lists the repositories of a project or every project of the organization when project is empty.
+func ListRepos(c *Client, project string, skipPrivate bool, skipForks bool) ([]Repository, error) {
+	path := "/_apis/git/repositories?api-version=" + APIVersion
+	if project != "" {
+		path = "/" + url.PathEscape(project) + path
+	}
+
+	var res struct {
+		Value []Repository `json:"value"`
+	}
+	if err := c.get(path, &res); err != nil {
+		return nil, err
+	}
+
+	// Skip disabled, private, or forks
+	var repos []Repository
+	for _, repo := range res.Value {
+		if repo.IsDisabled {
+			continue
+		}
+
+		if skipPrivate && repo.Project.Visibility != "public" {
+			continue
+		}
+
+		if skipForks && repo.IsFork {
+			continue
+		}
+
+		repos = append(repos, repo)
+	}
+
+	return repos, nil
+}
+
+func ConvertList(azureRepos []Repository) []tea.SourceRepository {
+	repos := make([]tea.SourceRepository, len(azureRepos))
+	for i := range repos {
+		repos[i] = Convert(azureRepos[i])
+	}
+	return repos
+}
+
+func Convert(r Repository) tea.SourceRepository {
+	return tea.SourceRepository{
+		SyncRepository: tea.SyncRepository{
+			DefaultBranch: strings.TrimPrefix(r.DefaultBranch, "refs/heads/"),
+			Private:       r.Project.Visibility != "public",
+			Empty:         gitea.OptionalBool(r.Size == 0),
+		},
+		Owner:   Owner(r.Project.Name),
+		Name:    r.Name,
+		Fork:    r.IsFork,
+		HTMLURL: r.WebURL,
+		URLS:    []string{CloneURL(r), r.WebURL},
+	}
+}
+
+// Owner returns the owner of repositories in a project, spaces are not allowed in owner names.
+func Owner(project string) string {
+	return strings.ReplaceAll(project, " ", "-")
+}
+
+// CloneURL returns the remote URL without the organization as username.
+func CloneURL(r Repository) string {
+	u, err := url.Parse(r.RemoteURL)
+	if err != nil {
+		return r.RemoteURL
+	}
+	u.User = nil
+
+	return u.String()
+}
+
+// GetOwner returns the project whose owner name is owner.
+func GetOwner(c *Client, owner string) (tea.SourceOwner, error) {
+	var res struct {
+		Value []Project `json:"value"`
+	}
+	if err := c.get("/_apis/projects?$top=1000&api-version="+APIVersion, &res); err != nil {
+		return tea.SourceOwner{}, err
+	}
+
+	for _, p := range res.Value {
+		if Owner(p.Name) != owner {
+			continue
+		}
+
+		visibility := gitea.VisibleTypePrivate
+		if p.Visibility == "public" {
+			visibility = gitea.VisibleTypePublic
+		}
+
+		return tea.SourceOwner{
+			Name:        owner,
+			FullName:    p.Name,
+			Description: p.Description,
+			Visibility:  visibility,
+		}, nil
+	}
+
+	return tea.SourceOwner{}, fmt.Errorf("project not found: %s", owner)
+}
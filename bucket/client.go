@@ -0,0 +1,161 @@
+package bucket
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"code.gitea.io/sdk/gitea"
+	"github.com/ItsNotGoodName/sync-gitea-mirrors/tea"
+)
+
+// CloudURL is the API of Bitbucket Cloud, any other URL is treated as Bitbucket Server/Data Center.
+const CloudURL = "https://api.bitbucket.org/2.0"
+
+// GitUsername is used for git authentication when the token is an access token without a username.
+const GitUsername = "x-token-auth"
+
+type link struct {
+	Name string `json:"name"`
+	Href string `json:"href"`
+}
+
+type Client struct {
+	URL      string
+	Username string
+	Token    string
+	HTTP     *http.Client
+
+	// projects maps owners of Bitbucket Server repositories to their project keys.
+	projects map[string]string
+}
+
+func NewClient(url, username, token string) *Client {
+	return &Client{
+		URL:      strings.TrimSuffix(url, "/"),
+		Username: username,
+		Token:    token,
+		HTTP:     http.DefaultClient,
+		projects: make(map[string]string),
+	}
+}
+
+func (c *Client) IsCloud() bool {
+	return c.URL == CloudURL
+}
+
+// GitAuth returns the credentials used by Gitea to clone repositories.
+func (c *Client) GitAuth() (string, string) {
+	if c.Username == "" {
+		return GitUsername, c.Token
+	}
+
+	return c.Username, c.Token
+}
+
+func ListRepos(c *Client, owner string, skipPrivate bool, skipForks bool) ([]tea.SourceRepository, error) {
+	var repos []tea.SourceRepository
+	if c.IsCloud() {
+		cloudRepos, err := ListCloudRepos(c, owner)
+		if err != nil {
+			return nil, err
+		}
+		for _, r := range cloudRepos {
+			repos = append(repos, ConvertCloud(r))
+		}
+	} else {
+		serverRepos, err := ListServerRepos(c, owner)
+		if err != nil {
+			return nil, err
+		}
+		for _, r := range serverRepos {
+			repos = append(repos, ConvertServer(r))
+		}
+	}
+
+	// Skip private or forks
+	var newRepos []tea.SourceRepository
+	for _, repo := range repos {
+		if skipPrivate && repo.Private {
+			continue
+		}
+
+		if skipForks && repo.Fork {
+			continue
+		}
+
+		newRepos = append(newRepos, repo)
+	}
+
+	return newRepos, nil
+}
+
+func ListRefs(c *Client, owner, repoName string) (tea.Refs, error) {
+	if c.IsCloud() {
+		return ListCloudRefs(c, owner, repoName)
+	}
+
+	return ListServerRefs(c, owner, repoName)
+}
+
+func GetOwner(c *Client, name string) (tea.SourceOwner, error) {
+	if c.IsCloud() {
+		return GetCloudOwner(c, name)
+	}
+
+	return GetServerOwner(c, name)
+}
+
+func (c *Client) get(url string, v any) error {
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return err
+	}
+	if c.Username == "" {
+		req.Header.Set("Authorization", "Bearer "+c.Token)
+	} else {
+		req.SetBasicAuth(c.Username, c.Token)
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.HTTP.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s: %s", url, resp.Status)
+	}
+
+	return json.NewDecoder(resp.Body).Decode(v)
+}
+
+// cloneURL returns the HTTP clone URL without credentials.
+func cloneURL(links []link) string {
+	for _, l := range links {
+		if l.Name != "https" && l.Name != "http" {
+			continue
+		}
+
+		u, err := url.Parse(l.Href)
+		if err != nil {
+			return l.Href
+		}
+		u.User = nil
+
+		return u.String()
+	}
+
+	return ""
+}
+
+func visibility(private bool) gitea.VisibleType {
+	if private {
+		return gitea.VisibleTypePrivate
+	}
+
+	return gitea.VisibleTypePublic
+}
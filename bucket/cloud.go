@@ -0,0 +1,131 @@
+package bucket
+
+import (
+	"fmt"
+	"net/url"
+	"time"
+
+	"code.gitea.io/sdk/gitea"
+	"github.com/ItsNotGoodName/sync-gitea-mirrors/tea"
+)
+
+type CloudRepository struct {
+	Slug        string    `json:"slug"`
+	Description string    `json:"description"`
+	Website     string    `json:"website"`
+	IsPrivate   bool      `json:"is_private"`
+	Size        int64     `json:"size"`
+	UpdatedOn   time.Time `json:"updated_on"`
+	Parent      *struct{} `json:"parent"`
+	MainBranch  *struct {
+		Name string `json:"name"`
+	} `json:"mainbranch"`
+	Workspace struct {
+		Slug string `json:"slug"`
+	} `json:"workspace"`
+	Links struct {
+		HTML   link   `json:"html"`
+		Avatar link   `json:"avatar"`
+		Clone  []link `json:"clone"`
+	} `json:"links"`
+}
+
+type cloudPage[T any] struct {
+	Values []T    `json:"values"`
+	Next   string `json:"next"`
+}
+
+func listCloud[T any](c *Client, next string) ([]T, error) {
+	var values []T
+	for next != "" {
+		var page cloudPage[T]
+		if err := c.get(next, &page); err != nil {
+			return nil, err
+		}
+		values = append(values, page.Values...)
+		next = page.Next
+	}
+
+	return values, nil
+}
+
+// ListCloudRepos lists the repositories of a workspace or every repository the user is a member of when workspace is empty.
+func ListCloudRepos(c *Client, workspace string) ([]CloudRepository, error) {
+	if workspace == "" {
+		return listCloud[CloudRepository](c, c.URL+"/repositories?role=member&pagelen=100")
+	}
+
+	return listCloud[CloudRepository](c, fmt.Sprintf("%s/repositories/%s?pagelen=100", c.URL, url.PathEscape(workspace)))
+}
+
+func ConvertCloud(r CloudRepository) tea.SourceRepository {
+	var defaultBranch string
+	if r.MainBranch != nil {
+		defaultBranch = r.MainBranch.Name
+	}
+
+	return tea.SourceRepository{
+		SyncRepository: tea.SyncRepository{
+			Description:   r.Description,
+			Website:       r.Website,
+			DefaultBranch: defaultBranch,
+			Private:       r.IsPrivate,
+			Empty:         gitea.OptionalBool(r.Size == 0),
+			PushedAt:      r.UpdatedOn,
+		},
+		Owner:     r.Workspace.Slug,
+		Name:      r.Slug,
+		Fork:      r.Parent != nil,
+		HTMLURL:   r.Links.HTML.Href,
+		AvatarURL: r.Links.Avatar.Href,
+		URLS:      []string{cloneURL(r.Links.Clone), r.Links.HTML.Href},
+	}
+}
+
+func ListCloudRefs(c *Client, workspace, slug string) (tea.Refs, error) {
+	type ref struct {
+		Name   string `json:"name"`
+		Target struct {
+			Hash string `json:"hash"`
+		} `json:"target"`
+	}
+
+	refs := make(tea.Refs)
+	for _, kind := range []string{"branches", "tags"} {
+		values, err := listCloud[ref](c, fmt.Sprintf("%s/repositories/%s/%s/refs/%s?pagelen=100", c.URL, url.PathEscape(workspace), url.PathEscape(slug), kind))
+		if err != nil {
+			return nil, err
+		}
+
+		prefix := "refs/heads/"
+		if kind == "tags" {
+			prefix = "refs/tags/"
+		}
+		for _, v := range values {
+			refs[prefix+v.Name] = v.Target.Hash
+		}
+	}
+
+	return refs, nil
+}
+
+func GetCloudOwner(c *Client, workspace string) (tea.SourceOwner, error) {
+	var w struct {
+		Slug      string `json:"slug"`
+		Name      string `json:"name"`
+		IsPrivate bool   `json:"is_private"`
+		Links     struct {
+			Avatar link `json:"avatar"`
+		} `json:"links"`
+	}
+	if err := c.get(fmt.Sprintf("%s/workspaces/%s", c.URL, url.PathEscape(workspace)), &w); err != nil {
+		return tea.SourceOwner{}, err
+	}
+
+	return tea.SourceOwner{
+		Name:       w.Slug,
+		FullName:   w.Name,
+		AvatarURL:  w.Links.Avatar.Href,
+		Visibility: visibility(w.IsPrivate),
+	}, nil
+}
@@ -0,0 +1,171 @@
+package bucket
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/ItsNotGoodName/sync-gitea-mirrors/tea"
+)
+
+type ServerRepository struct {
+	Slug        string    `json:"slug"`
+	Description string    `json:"description"`
+	Public      bool      `json:"public"`
+	Archived    bool      `json:"archived"`
+	Origin      *struct{} `json:"origin"`
+	Project     struct {
+		Key   string `json:"key"`
+		Type  string `json:"type"`
+		Owner *struct {
+			Slug string `json:"slug"`
+		} `json:"owner"`
+	} `json:"project"`
+	Links struct {
+		Clone []link `json:"clone"`
+		Self  []link `json:"self"`
+	} `json:"links"`
+}
+
+type serverPage[T any] struct {
+	Values        []T  `json:"values"`
+	IsLastPage    bool `json:"isLastPage"`
+	NextPageStart int  `json:"nextPageStart"`
+}
+
+func listServer[T any](c *Client, path string) ([]T, error) {
+	sep := "?"
+	if strings.Contains(path, "?") {
+		sep = "&"
+	}
+
+	var values []T
+	start := 0
+	for {
+		var page serverPage[T]
+		if err := c.get(fmt.Sprintf("%s/rest/api/1.0%s%slimit=100&start=%d", c.URL, path, sep, start), &page); err != nil {
+			return nil, err
+		}
+		values = append(values, page.Values...)
+		if page.IsLastPage {
+			return values, nil
+		}
+		start = page.NextPageStart
+	}
+}
+
+// ListServerRepos lists the repositories of a project or every repository the user can access when project is empty.
+func ListServerRepos(c *Client, project string) ([]ServerRepository, error) {
+	path := "/repos"
+	if project != "" {
+		path = fmt.Sprintf("/projects/%s/repos", url.PathEscape(project))
+	}
+
+	repos, err := listServer[ServerRepository](c, path)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, r := range repos {
+		c.projects[serverOwner(r)] = r.Project.Key
+	}
+
+	return repos, nil
+}
+
+// serverOwner returns the project key or the user slug of personal projects whose keys (e.g. "~USER") are not valid owners.
+func serverOwner(r ServerRepository) string {
+	if r.Project.Type == "PERSONAL" && r.Project.Owner != nil {
+		return r.Project.Owner.Slug
+	}
+
+	return r.Project.Key
+}
+
+// projectKey returns the project key of an owner returned by serverOwner.
+func (c *Client) projectKey(owner string) string {
+	if key, ok := c.projects[owner]; ok {
+		return key
+	}
+
+	return owner
+}
+
+func ConvertServer(r ServerRepository) tea.SourceRepository {
+	var htmlURL string
+	if len(r.Links.Self) > 0 {
+		htmlURL = r.Links.Self[0].Href
+	}
+
+	return tea.SourceRepository{
+		SyncRepository: tea.SyncRepository{
+			Description: r.Description,
+			Private:     !r.Public,
+			Archived:    r.Archived,
+		},
+		Owner:   serverOwner(r),
+		Name:    r.Slug,
+		Fork:    r.Origin != nil,
+		HTMLURL: htmlURL,
+		URLS:    []string{cloneURL(r.Links.Clone), htmlURL},
+	}
+}
+
+func ListServerRefs(c *Client, owner, slug string) (tea.Refs, error) {
+	project := c.projectKey(owner)
+
+	type ref struct {
+		ID           string `json:"id"`
+		LatestCommit string `json:"latestCommit"`
+	}
+
+	refs := make(tea.Refs)
+	for _, kind := range []string{"branches", "tags"} {
+		values, err := listServer[ref](c, fmt.Sprintf("/projects/%s/repos/%s/%s", url.PathEscape(project), url.PathEscape(slug), kind))
+		if err != nil {
+			return nil, err
+		}
+
+		for _, v := range values {
+			refs[v.ID] = v.LatestCommit
+		}
+	}
+
+	return refs, nil
+}
+
+func GetServerOwner(c *Client, owner string) (tea.SourceOwner, error) {
+	project := c.projectKey(owner)
+	if strings.HasPrefix(project, "~") {
+		var u struct {
+			Slug        string `json:"slug"`
+			DisplayName string `json:"displayName"`
+		}
+		if err := c.get(fmt.Sprintf("%s/rest/api/1.0/users/%s", c.URL, url.PathEscape(owner)), &u); err != nil {
+			return tea.SourceOwner{}, err
+		}
+
+		return tea.SourceOwner{
+			Name:       u.Slug,
+			FullName:   u.DisplayName,
+			Visibility: visibility(true),
+		}, nil
+	}
+
+	var p struct {
+		Key         string `json:"key"`
+		Name        string `json:"name"`
+		Description string `json:"description"`
+		Public      bool   `json:"public"`
+	}
+	if err := c.get(fmt.Sprintf("%s/rest/api/1.0/projects/%s", c.URL, url.PathEscape(project)), &p); err != nil {
+		return tea.SourceOwner{}, err
+	}
+
+	return tea.SourceOwner{
+		Name:        owner,
+		FullName:    p.Name,
+		Description: p.Description,
+		Visibility:  visibility(!p.Public),
+	}, nil
+}
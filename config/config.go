@@ -4,6 +4,7 @@ import (
 	"flag"
 	"fmt"
 	"strings"
+	"text/template"
 
 	"github.com/caarlos0/env/v7"
 )
@@ -11,13 +12,38 @@ import (
 const DefaultDestMirrorInterval = "8h0m0s"
 const DefaultDaemonError = 300
 const MinimumDaemon = 60
+const DefaultMigrateWait = 300
+const DefaultMigrateTimeout = 3600
+const DefaultMigrateRetries = 1
+const DefaultHealthStale = 604800
 const GiteaURL = "https://gitea.com"
+const ForgejoURL = "https://codeberg.org"
+const BitbucketURL = "https://api.bitbucket.org/2.0"
+const SourceHutURL = "https://git.sr.ht"
+const AzureURL = "https://dev.azure.com"
+const DefaultGitHubGistNameTemplate = "gist-{{.ID}}"
 
 type Source string
 
 const (
-	SourceGitHub Source = "github"
-	SourceGitea  Source = "gitea"
+	SourceGitHub    Source = "github"
+	SourceGitea     Source = "gitea"
+	SourceForgejo   Source = "forgejo"
+	SourceBitbucket Source = "bitbucket"
+	SourceGogs      Source = "gogs"
+	SourceOneDev    Source = "onedev"
+	SourceGitBucket Source = "gitbucket"
+	SourceURLs      Source = "urls"
+	SourceSourceHut Source = "sourcehut"
+	SourceAzure     Source = "azure"
+)
+
+type Collision string
+
+const (
+	CollisionSkip   Collision = "skip"
+	CollisionSuffix Collision = "suffix"
+	CollisionFail   Collision = "fail"
 )
 
 type Config struct {
@@ -29,29 +55,91 @@ type Config struct {
 	DaemonSkipFirst bool `env:"DAEMON_SKIP_FIRST"`
 	DaemonExitError bool `env:"DAEMON_EXIT_ERROR"`
 
-	Source      Source
-	GitHubOwner string   `env:"GITHUB_OWNER"`
-	GitHubToken string   `env:"GITHUB_TOKEN"`
-	GiteaOwner  string   `env:"GITEA_OWNER"`
-	GiteaToken  string   `env:"GITEA_TOKEN"`
-	GiteaURL    string   `env:"GITEA_URL"`
-	SkipRepos   []string `env:"SKIP_REPOS" envSeparator:" "`
-	SkipForks   bool     `env:"SKIP_FORKS"`
-	SkipPrivate bool     `env:"SKIP_PRIVATE"`
+	Source                 Source   `env:"SOURCE"`
+	GitHubOwner            []string `env:"GITHUB_OWNER" envSeparator:" "`
+	GitHubAffiliation      string   `env:"GITHUB_AFFILIATION"`
+	GitHubStarred          bool     `env:"GITHUB_STARRED"`
+	GitHubSearch           string   `env:"GITHUB_SEARCH"`
+	GitHubGists            bool     `env:"GITHUB_GISTS"`
+	GitHubGistNameTemplate string   `env:"GITHUB_GIST_NAME_TEMPLATE"`
+	GitHubGistName         *template.Template
+	GitHubToken            string   `env:"GITHUB_TOKEN"`
+	GitHubURL              string   `env:"GITHUB_URL"`
+	GitHubUploadURL        string   `env:"GITHUB_UPLOAD_URL"`
+	GiteaOwner             string   `env:"GITEA_OWNER"`
+	GiteaToken             string   `env:"GITEA_TOKEN"`
+	GiteaURL               string   `env:"GITEA_URL"`
+	ForgejoOwner           string   `env:"FORGEJO_OWNER"`
+	ForgejoToken           string   `env:"FORGEJO_TOKEN"`
+	ForgejoURL             string   `env:"FORGEJO_URL"`
+	BitbucketOwner         string   `env:"BITBUCKET_OWNER"`
+	BitbucketUsername      string   `env:"BITBUCKET_USERNAME"`
+	BitbucketToken         string   `env:"BITBUCKET_TOKEN"`
+	BitbucketURL           string   `env:"BITBUCKET_URL"`
+	GogsOwner              string   `env:"GOGS_OWNER"`
+	GogsToken              string   `env:"GOGS_TOKEN"`
+	GogsURL                string   `env:"GOGS_URL"`
+	OneDevOwner            string   `env:"ONEDEV_OWNER"`
+	OneDevUsername         string   `env:"ONEDEV_USERNAME"`
+	OneDevToken            string   `env:"ONEDEV_TOKEN"`
+	OneDevURL              string   `env:"ONEDEV_URL"`
+	GitBucketOwner         string   `env:"GITBUCKET_OWNER"`
+	GitBucketToken         string   `env:"GITBUCKET_TOKEN"`
+	GitBucketURL           string   `env:"GITBUCKET_URL"`
+	URLs                   []string `env:"URLS" envSeparator:" "`
+	URLEntries             []URLEntry
+	URLsFile               string   `env:"URLS_FILE"`
+	URLsUsername           string   `env:"URLS_USERNAME"`
+	URLsPassword           string   `env:"URLS_PASSWORD"`
+	SourceHutOwner         string   `env:"SRHT_OWNER"`
+	SourceHutToken         string   `env:"SRHT_TOKEN"`
+	SourceHutURL           string   `env:"SRHT_URL"`
+	AzureOrg               string   `env:"AZURE_ORG"`
+	AzureProject           string   `env:"AZURE_PROJECT"`
+	AzureToken             string   `env:"AZURE_TOKEN"`
+	AzureURL               string   `env:"AZURE_URL"`
+	SkipRepos              []string `env:"SKIP_REPOS" envSeparator:" "`
+	SkipForks              bool     `env:"SKIP_FORKS"`
+	SkipPrivate            bool     `env:"SKIP_PRIVATE"`
 
-	MigrateWiki bool `env:"MIGRATE_WIKI"`
-	MigrateLFS  bool `env:"MIGRATE_LFS"`
+	MigrateWiki    bool `env:"MIGRATE_WIKI"`
+	MigrateLFS     bool `env:"MIGRATE_LFS"`
+	MigrateWait    int  `env:"MIGRATE_WAIT"`
+	MigrateTimeout int  `env:"MIGRATE_TIMEOUT"`
+	MigrateRetries int  `env:"MIGRATE_RETRIES"`
+
+	Verify bool `env:"VERIFY"`
+
+	HealthCheck  bool `env:"HEALTH_CHECK"`
+	HealthStale  int  `env:"HEALTH_STALE"`
+	HealthRepair bool `env:"HEALTH_REPAIR"`
 
 	SyncAll            bool `env:"SYNC_ALL"`
 	SyncTopics         bool `env:"SYNC_TOPICS"`
 	SyncDescription    bool `env:"SYNC_DESCRIPTION"`
+	SyncWebsite        bool `env:"SYNC_WEBSITE"`
+	SyncWebsiteHTMLURL bool `env:"SYNC_WEBSITE_HTML_URL"`
+	SyncDefaultBranch  bool `env:"SYNC_DEFAULT_BRANCH"`
 	SyncVisibility     bool `env:"SYNC_VISIBILITY"`
 	SyncMirrorInterval bool `env:"SYNC_MIRROR_INTERVAL"`
+	SyncArchived       bool `env:"SYNC_ARCHIVED"`
+	SyncAvatar         bool `env:"SYNC_AVATAR"`
 
-	DestURL            string `env:"DEST_URL"`
-	DestToken          string `env:"DEST_TOKEN"`
-	DestOwner          string `env:"DEST_OWNER"`
-	DestMirrorInterval string `env:"DEST_MIRROR_INTERVAL"`
+	DestURL            string          `env:"DEST_URL"`
+	DestType           DestinationType `env:"DEST_TYPE"`
+	DestToken          string          `env:"DEST_TOKEN"`
+	DestOwner          string          `env:"DEST_OWNER"`
+	DestMirrorInterval string          `env:"DEST_MIRROR_INTERVAL"`
+	DestOwnerMap       []string        `env:"DEST_OWNER_MAP" envSeparator:" "`
+	DestCreateOrgs     bool            `env:"DEST_CREATE_ORGS"`
+	DestNameTemplate   string          `env:"DEST_NAME_TEMPLATE"`
+	DestName           *template.Template
+	DestCollision      Collision `env:"DEST_COLLISION"`
+	DestAdoptHosts     []string  `env:"DEST_ADOPT_HOSTS" envSeparator:" "`
+	Destinations       []Destination
+
+	DescriptionTemplate string `env:"DESCRIPTION_TEMPLATE"`
+	Description         *template.Template
 }
 
 func New() *Config {
@@ -63,29 +151,91 @@ func New() *Config {
 	flag.IntVar(&cfg.DaemonError, "daemon-error", DefaultDaemonError, `Seconds between each run when error occurs (e.g. "300" is a 5 minutes).`)
 	flag.BoolVar(&cfg.DaemonSkipFirst, "daemon-skip-first", false, "Skip first run.")
 	flag.BoolVar(&cfg.DaemonExitError, "daemon-exit-error", false, "Exit daemon when error occurs.")
-	flag.StringVar(&cfg.GitHubOwner, "github-owner", "", "Owner of GitHub source repositories.")
+	source := flag.String("source", "", `Source of repositories ("github", "gitea", "forgejo", "bitbucket", "gogs", "onedev", "gitbucket", "urls", "sourcehut", or "azure"), inferred from the other options when empty.`)
+	githubOwner := flag.String("github-owner", "", "List of space seperated users or organizations of GitHub source repositories.")
+	flag.StringVar(&cfg.GitHubAffiliation, "github-affiliation", "", `List of comma seperated affiliations of the authenticated user's GitHub source repositories ("owner", "collaborator", or "organization_member"), can not be used with "github-owner", "github-starred", or "github-search".`)
+	flag.BoolVar(&cfg.GitHubStarred, "github-starred", false, `Mirror repositories starred by "github-owner" or the authenticated user instead of their own repositories.`)
+	flag.StringVar(&cfg.GitHubSearch, "github-search", "", `Mirror repositories matching the GitHub search query instead of the repositories of "github-owner" (e.g. "org:kubernetes topic:operator archived:false").`)
+	flag.BoolVar(&cfg.GitHubGists, "github-gists", false, `Also mirror gists of "github-owner" or the authenticated user.`)
+	flag.StringVar(&cfg.GitHubGistNameTemplate, "github-gist-name-template", DefaultGitHubGistNameTemplate, `Go template for the name of gists (e.g. "gist-{{.Filename}}").`)
 	flag.StringVar(&cfg.GitHubToken, "github-token", "", "Token for accessing GitHub.")
+	flag.StringVar(&cfg.GitHubURL, "github-url", "", "URL of the GitHub Enterprise Server instance, empty for github.com.")
+	flag.StringVar(&cfg.GitHubUploadURL, "github-upload-url", "", `Upload URL of the GitHub Enterprise Server instance, same as "github-url" when empty.`)
 	flag.StringVar(&cfg.GiteaOwner, "gitea-owner", "", "Owner of Gitea source repositories.")
 	flag.StringVar(&cfg.GiteaToken, "gitea-token", "", "Token for accessing the source Gitea instance.")
 	flag.StringVar(&cfg.GiteaURL, "gitea-url", GiteaURL, "URL of the source Gitea instance.")
+	flag.StringVar(&cfg.ForgejoOwner, "forgejo-owner", "", "Owner of Forgejo source repositories.")
+	flag.StringVar(&cfg.ForgejoToken, "forgejo-token", "", "Token for accessing the source Forgejo instance.")
+	flag.StringVar(&cfg.ForgejoURL, "forgejo-url", ForgejoURL, "URL of the source Forgejo instance.")
+	flag.StringVar(&cfg.BitbucketOwner, "bitbucket-owner", "", "Workspace (Cloud) or project key (Server/Data Center) of Bitbucket source repositories.")
+	flag.StringVar(&cfg.BitbucketUsername, "bitbucket-username", "", "Username for accessing Bitbucket, empty when using an access token.")
+	flag.StringVar(&cfg.BitbucketToken, "bitbucket-token", "", "App password, HTTP access token, or access token for accessing Bitbucket.")
+	flag.StringVar(&cfg.BitbucketURL, "bitbucket-url", BitbucketURL, "URL of the Bitbucket API for Cloud or the URL of the Bitbucket Server/Data Center instance.")
+	flag.StringVar(&cfg.GogsOwner, "gogs-owner", "", "Owner of Gogs source repositories.")
+	flag.StringVar(&cfg.GogsToken, "gogs-token", "", "Token for accessing the source Gogs instance.")
+	flag.StringVar(&cfg.GogsURL, "gogs-url", "", "URL of the source Gogs instance.")
+	flag.StringVar(&cfg.OneDevOwner, "onedev-owner", "", "Path of the parent project of OneDev source projects.")
+	flag.StringVar(&cfg.OneDevUsername, "onedev-username", "", "Username for accessing the source OneDev instance.")
+	flag.StringVar(&cfg.OneDevToken, "onedev-token", "", "Access token for accessing the source OneDev instance.")
+	flag.StringVar(&cfg.OneDevURL, "onedev-url", "", "URL of the source OneDev instance.")
+	flag.StringVar(&cfg.GitBucketOwner, "gitbucket-owner", "", "Owner of GitBucket source repositories.")
+	flag.StringVar(&cfg.GitBucketToken, "gitbucket-token", "", "Token for accessing the source GitBucket instance.")
+	flag.StringVar(&cfg.GitBucketURL, "gitbucket-url", "", "URL of the source GitBucket instance.")
+	urls := flag.String("urls", "", `List of space seperated git urls in the form "[owner/name=]url".`)
+	flag.StringVar(&cfg.URLsFile, "urls-file", "", "File with a git url in the form \"[owner/name=]url\" per line.")
+	flag.StringVar(&cfg.URLsUsername, "urls-username", "", "Username for accessing the git urls.")
+	flag.StringVar(&cfg.URLsPassword, "urls-password", "", "Password or token for accessing the git urls.")
+	flag.StringVar(&cfg.SourceHutOwner, "srht-owner", "", "Username of SourceHut source repositories.")
+	flag.StringVar(&cfg.SourceHutToken, "srht-token", "", "Personal access token for accessing SourceHut.")
+	flag.StringVar(&cfg.SourceHutURL, "srht-url", SourceHutURL, "URL of the SourceHut git service.")
+	flag.StringVar(&cfg.AzureOrg, "azure-org", "", "Organization (or collection) of Azure DevOps source repositories.")
+	flag.StringVar(&cfg.AzureProject, "azure-project", "", "Project of Azure DevOps source repositories.")
+	flag.StringVar(&cfg.AzureToken, "azure-token", "", "Personal access token for accessing Azure DevOps.")
+	flag.StringVar(&cfg.AzureURL, "azure-url", AzureURL, "URL of Azure DevOps Services or Server.")
 	skipRepos := flag.String("skip-repos", "", `List of space seperated repositories to not sync (e.g. "repo1 repo2 repo3").`)
 	flag.BoolVar(&cfg.SkipForks, "skip-forks", false, "Skip fork repositories.")
 	flag.BoolVar(&cfg.SkipPrivate, "skip-private", false, "Skip private repositories.")
 	flag.BoolVar(&cfg.MigrateWiki, "migrate-wiki", false, "Migrate wiki from source repositories.")
 	flag.BoolVar(&cfg.MigrateLFS, "migrate-lfs", false, "Migrate lfs from source repositories.")
-	flag.BoolVar(&cfg.SyncAll, "sync-all", false, "Sync everything.")
+	flag.IntVar(&cfg.MigrateWait, "migrate-wait", DefaultMigrateWait, "Seconds to wait for a migration to finish before syncing it in a later run.")
+	flag.IntVar(&cfg.MigrateTimeout, "migrate-timeout", DefaultMigrateTimeout, "Seconds until an unfinished migration is deleted and migrated again.")
+	flag.IntVar(&cfg.MigrateRetries, "migrate-retries", DefaultMigrateRetries, "Number of times a failed migration is deleted and migrated again.")
+	flag.BoolVar(&cfg.Verify, "verify", false, "Compare branches and tags of mirrors with their source and mirror sync on drift.")
+	flag.BoolVar(&cfg.HealthCheck, "health-check", false, "Check for mirrors that are empty or stale and mirror sync them.")
+	flag.IntVar(&cfg.HealthStale, "health-stale", DefaultHealthStale, `Seconds a mirror can be behind its source before it is stale where 0 disables the check (e.g. "604800" is a week).`)
+	flag.BoolVar(&cfg.HealthRepair, "health-repair", false, "Delete and migrate again mirrors that are still empty or stale in a later daemon run after they were synced, requires health-check and daemon.")
+	flag.BoolVar(&cfg.SyncAll, "sync-all", false, "Sync everything except archived and avatar.")
 	flag.BoolVar(&cfg.SyncTopics, "sync-topics", false, "Sync topics of repository.")
 	flag.BoolVar(&cfg.SyncDescription, "sync-description", false, "Sync description of repository.")
+	flag.BoolVar(&cfg.SyncWebsite, "sync-website", false, "Sync website of repository.")
+	flag.BoolVar(&cfg.SyncWebsiteHTMLURL, "sync-website-html-url", false, "Use URL of source repository as website instead of its homepage.")
+	flag.BoolVar(&cfg.SyncDefaultBranch, "sync-default-branch", false, "Sync default branch of repository.")
 	flag.BoolVar(&cfg.SyncVisibility, "sync-visibility", false, "Sync private/public status of repository.")
 	flag.BoolVar(&cfg.SyncMirrorInterval, "sync-mirror-interval", false, "Disable periodic sync if source repository is archived.")
+	flag.BoolVar(&cfg.SyncArchived, "sync-archived", false, "Archive repository if source repository is archived.")
+	flag.BoolVar(&cfg.SyncAvatar, "sync-avatar", false, "Sync avatar of repository.")
 	flag.StringVar(&cfg.DestURL, "dest-url", "", "URL of the destination Gitea instance. (required)")
+	destType := flag.String("dest-type", "", `Type of the destination instance ("gitea" or "forgejo"), detected when empty.`)
 	flag.StringVar(&cfg.DestToken, "dest-token", "", "Token for accessing the destination Gitea instance. (required)")
 	flag.StringVar(&cfg.DestOwner, "dest-owner", "", "Owner of the mirrored repositories in the destination Gitea instance.")
 	flag.StringVar(&cfg.DestMirrorInterval, "dest-mirror-interval", DefaultDestMirrorInterval, "Default mirror interval for new migrations in the destination Gitea instance.")
+	destOwnerMap := flag.String("dest-owner-map", "", `List of space seperated rules mapping source owners to destination owners (e.g. "github:ourcompany/*=company-mirrors alice=alice").`)
+	flag.BoolVar(&cfg.DestCreateOrgs, "dest-create-orgs", false, "Create missing organizations in the destination Gitea instance.")
+	flag.StringVar(&cfg.DestNameTemplate, "dest-name-template", "", `Go template for the name of mirrored repositories (e.g. "{{.Owner}}-{{.Name}}").`)
+	destCollision := flag.String("dest-collision", string(CollisionSkip), `Strategy when repositories have the same destination ("skip", "suffix", or "fail").`)
+	destAdoptHosts := flag.String("dest-adopt-hosts", "", `List of space seperated old or alternate hosts of the source whose mirrors with the same path are adopted (e.g. "github.com git.example.com").`)
+	flag.StringVar(&cfg.DescriptionTemplate, "description-template", "", `Go template for the description of mirrored repositories (e.g. "{{.Description}} (mirror of {{.HTMLURL}})").`)
 
 	flag.Parse()
 
+	cfg.Source = Source(*source)
+	cfg.GitHubOwner = strings.Fields(*githubOwner)
+	cfg.URLs = strings.Fields(*urls)
 	cfg.SkipRepos = strings.Split(*skipRepos, " ")
+	cfg.DestType = DestinationType(*destType)
+	cfg.DestOwnerMap = strings.Fields(*destOwnerMap)
+	cfg.DestCollision = Collision(*destCollision)
+	cfg.DestAdoptHosts = strings.Fields(*destAdoptHosts)
 
 	return &cfg
 }
@@ -100,20 +250,31 @@ func (cfg *Config) ParseAndValidate() error {
 		cfg.DaemonError = cfg.Daemon
 	}
 
-	if cfg.SyncAll {
-		cfg.SyncDescription = true
-		cfg.SyncMirrorInterval = true
-		cfg.SyncTopics = true
-		cfg.SyncVisibility = true
-	}
-
 	// Infer source
-	if cfg.GitHubOwner != "" || cfg.GitHubToken != "" {
-		cfg.Source = SourceGitHub
-	} else if cfg.GiteaOwner != "" || cfg.GiteaToken != "" || cfg.GiteaURL != "" {
-		cfg.Source = SourceGitea
-	} else {
-		return fmt.Errorf("source for repositories not setup")
+	if cfg.Source == "" {
+		if len(cfg.GitHubOwner) != 0 || cfg.GitHubToken != "" || cfg.GitHubSearch != "" || cfg.GitHubURL != "" {
+			cfg.Source = SourceGitHub
+		} else if cfg.ForgejoOwner != "" || cfg.ForgejoToken != "" {
+			cfg.Source = SourceForgejo
+		} else if cfg.BitbucketOwner != "" || cfg.BitbucketToken != "" {
+			cfg.Source = SourceBitbucket
+		} else if cfg.GogsOwner != "" || cfg.GogsToken != "" || cfg.GogsURL != "" {
+			cfg.Source = SourceGogs
+		} else if cfg.OneDevOwner != "" || cfg.OneDevToken != "" || cfg.OneDevURL != "" {
+			cfg.Source = SourceOneDev
+		} else if cfg.GitBucketOwner != "" || cfg.GitBucketToken != "" || cfg.GitBucketURL != "" {
+			cfg.Source = SourceGitBucket
+		} else if len(cfg.URLs) != 0 || cfg.URLsFile != "" {
+			cfg.Source = SourceURLs
+		} else if cfg.SourceHutOwner != "" || cfg.SourceHutToken != "" {
+			cfg.Source = SourceSourceHut
+		} else if cfg.AzureOrg != "" || cfg.AzureToken != "" {
+			cfg.Source = SourceAzure
+		} else if cfg.GiteaOwner != "" || cfg.GiteaToken != "" || cfg.GiteaURL != "" {
+			cfg.Source = SourceGitea
+		} else {
+			return fmt.Errorf("source for repositories not setup")
+		}
 	}
 
 	// Validate source config
@@ -122,6 +283,28 @@ func (cfg *Config) ParseAndValidate() error {
 		if cfg.GitHubToken == "" {
 			return fmt.Errorf("GITHUB_TOKEN not set")
 		}
+		if cfg.GitHubUploadURL != "" && cfg.GitHubURL == "" {
+			return fmt.Errorf("GITHUB_UPLOAD_URL set without GITHUB_URL")
+		}
+		if cfg.GitHubStarred && cfg.GitHubSearch != "" {
+			return fmt.Errorf("GITHUB_STARRED and GITHUB_SEARCH can not be used together")
+		}
+		if cfg.GitHubAffiliation != "" {
+			if len(cfg.GitHubOwner) != 0 {
+				return fmt.Errorf("GITHUB_AFFILIATION and GITHUB_OWNER can not be used together")
+			}
+			if cfg.GitHubStarred {
+				return fmt.Errorf("GITHUB_AFFILIATION and GITHUB_STARRED can not be used together")
+			}
+			if cfg.GitHubSearch != "" {
+				return fmt.Errorf("GITHUB_AFFILIATION and GITHUB_SEARCH can not be used together")
+			}
+			for _, affiliation := range strings.Split(cfg.GitHubAffiliation, ",") {
+				if affiliation != "owner" && affiliation != "collaborator" && affiliation != "organization_member" {
+					return fmt.Errorf("invalid GITHUB_AFFILIATION: %s", affiliation)
+				}
+			}
+		}
 	case SourceGitea:
 		if cfg.GiteaToken == "" {
 			return fmt.Errorf("GITEA_TOKEN not set")
@@ -129,16 +312,139 @@ func (cfg *Config) ParseAndValidate() error {
 		if cfg.GiteaURL == "" {
 			return fmt.Errorf("GITEA_URL not set")
 		}
+	case SourceForgejo:
+		if cfg.ForgejoToken == "" {
+			return fmt.Errorf("FORGEJO_TOKEN not set")
+		}
+		if cfg.ForgejoURL == "" {
+			return fmt.Errorf("FORGEJO_URL not set")
+		}
+	case SourceBitbucket:
+		if cfg.BitbucketToken == "" {
+			return fmt.Errorf("BITBUCKET_TOKEN not set")
+		}
+		if cfg.BitbucketURL == "" {
+			return fmt.Errorf("BITBUCKET_URL not set")
+		}
+	case SourceGogs:
+		if cfg.GogsToken == "" {
+			return fmt.Errorf("GOGS_TOKEN not set")
+		}
+		if cfg.GogsURL == "" {
+			return fmt.Errorf("GOGS_URL not set")
+		}
+	case SourceOneDev:
+		if cfg.OneDevUsername == "" {
+			return fmt.Errorf("ONEDEV_USERNAME not set")
+		}
+		if cfg.OneDevToken == "" {
+			return fmt.Errorf("ONEDEV_TOKEN not set")
+		}
+		if cfg.OneDevURL == "" {
+			return fmt.Errorf("ONEDEV_URL not set")
+		}
+	case SourceGitBucket:
+		if cfg.GitBucketToken == "" {
+			return fmt.Errorf("GITBUCKET_TOKEN not set")
+		}
+		if cfg.GitBucketURL == "" {
+			return fmt.Errorf("GITBUCKET_URL not set")
+		}
+	case SourceURLs:
+		if len(cfg.URLs) == 0 && cfg.URLsFile == "" {
+			return fmt.Errorf("URLS or URLS_FILE not set")
+		}
+		cfg.URLEntries = nil
+		for _, u := range cfg.URLs {
+			entry, err := ParseURLEntry(u)
+			if err != nil {
+				return err
+			}
+			cfg.URLEntries = append(cfg.URLEntries, entry)
+		}
+		if _, err := cfg.GetURLEntries(); err != nil {
+			return err
+		}
+	case SourceSourceHut:
+		if cfg.SourceHutToken == "" {
+			return fmt.Errorf("SRHT_TOKEN not set")
+		}
+		if cfg.SourceHutURL == "" {
+			return fmt.Errorf("SRHT_URL not set")
+		}
+	case SourceAzure:
+		if cfg.AzureOrg == "" {
+			return fmt.Errorf("AZURE_ORG not set")
+		}
+		if cfg.AzureToken == "" {
+			return fmt.Errorf("AZURE_TOKEN not set")
+		}
+		if cfg.AzureURL == "" {
+			return fmt.Errorf("AZURE_URL not set")
+		}
 	default:
 		return fmt.Errorf("invalid SOURCE: %s", cfg.Source)
 	}
 
-	if cfg.DestURL == "" {
-		return fmt.Errorf("DEST_URL not set")
+	dests, err := parseDestinations(cfg.destination())
+	if err != nil {
+		return err
+	}
+	cfg.Destinations = dests
+
+	switch cfg.DestCollision {
+	case CollisionSkip, CollisionSuffix, CollisionFail:
+	default:
+		return fmt.Errorf("invalid DEST_COLLISION: %s", cfg.DestCollision)
+	}
+
+	if cfg.DestNameTemplate != "" {
+		tmpl, err := parseTemplate("name", cfg.DestNameTemplate)
+		if err != nil {
+			return fmt.Errorf("invalid DEST_NAME_TEMPLATE: %w", err)
+		}
+		cfg.DestName = tmpl
+	}
+
+	if cfg.GitHubGists {
+		tmpl, err := parseTemplate("gist-name", cfg.GitHubGistNameTemplate)
+		if err != nil {
+			return fmt.Errorf("invalid GITHUB_GIST_NAME_TEMPLATE: %w", err)
+		}
+		cfg.GitHubGistName = tmpl
+	}
+
+	if cfg.DescriptionTemplate != "" {
+		tmpl, err := parseTemplate("description", cfg.DescriptionTemplate)
+		if err != nil {
+			return fmt.Errorf("invalid DESCRIPTION_TEMPLATE: %w", err)
+		}
+		cfg.Description = tmpl
+	}
+
+	if cfg.MigrateWait < 0 {
+		return fmt.Errorf("MIGRATE_WAIT is negative: %d", cfg.MigrateWait)
+	}
+
+	if cfg.MigrateTimeout < cfg.MigrateWait {
+		return fmt.Errorf("MIGRATE_TIMEOUT is less than MIGRATE_WAIT: %d", cfg.MigrateTimeout)
+	}
+
+	if cfg.MigrateRetries < 0 {
+		return fmt.Errorf("MIGRATE_RETRIES is negative: %d", cfg.MigrateRetries)
+	}
+
+	if cfg.HealthStale < 0 {
+		return fmt.Errorf("HEALTH_STALE is negative: %d", cfg.HealthStale)
 	}
 
-	if cfg.DestToken == "" {
-		return fmt.Errorf("DEST_TOKEN not set")
+	// Broken mirrors are only repaired by a later daemon run after they were synced
+	if cfg.HealthRepair && !cfg.HealthCheck {
+		return fmt.Errorf("HEALTH_REPAIR set without HEALTH_CHECK")
+	}
+
+	if cfg.HealthRepair && cfg.Daemon == 0 {
+		return fmt.Errorf("HEALTH_REPAIR set without DAEMON")
 	}
 
 	if cfg.Daemon < MinimumDaemon && cfg.Daemon != 0 {
@@ -151,3 +457,11 @@ func (cfg *Config) ParseAndValidate() error {
 
 	return nil
 }
+
+func parseTemplate(name, text string) (*template.Template, error) {
+	return template.New(name).Funcs(template.FuncMap{
+		"lower":   strings.ToLower,
+		"upper":   strings.ToUpper,
+		"replace": strings.ReplaceAll,
+	}).Parse(text)
+}
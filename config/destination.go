@@ -0,0 +1,142 @@
+package config
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/caarlos0/env/v7"
+)
+
+// MaximumDestinations is the largest number of destinations that will be looked up (e.g. "DEST9_URL").
+const MaximumDestinations = 9
+
+type DestinationType string
+
+const (
+	DestinationTypeGitea   DestinationType = "gitea"
+	DestinationTypeForgejo DestinationType = "forgejo"
+)
+
+// Destination is a Gitea instance where repositories are mirrored to.
+// Additional destinations are configured with environment variables prefixed by "DEST<n>_" (e.g. "DEST2_URL" and "DEST2_SYNC_ALL"), unset variables are inherited from the first destination except for the token.
+type Destination struct {
+	Name string
+
+	URL            string          `env:"URL"`
+	Type           DestinationType `env:"TYPE"`
+	Token          string          `env:"TOKEN"`
+	Owner          string          `env:"OWNER"`
+	OwnerMap       []string        `env:"OWNER_MAP" envSeparator:" "`
+	OwnerRules     []OwnerRule
+	CreateOrgs     bool   `env:"CREATE_ORGS"`
+	MirrorInterval string `env:"MIRROR_INTERVAL"`
+
+	SyncAll            bool `env:"SYNC_ALL"`
+	SyncTopics         bool `env:"SYNC_TOPICS"`
+	SyncDescription    bool `env:"SYNC_DESCRIPTION"`
+	SyncWebsite        bool `env:"SYNC_WEBSITE"`
+	SyncWebsiteHTMLURL bool `env:"SYNC_WEBSITE_HTML_URL"`
+	SyncDefaultBranch  bool `env:"SYNC_DEFAULT_BRANCH"`
+	SyncVisibility     bool `env:"SYNC_VISIBILITY"`
+	SyncMirrorInterval bool `env:"SYNC_MIRROR_INTERVAL"`
+	SyncArchived       bool `env:"SYNC_ARCHIVED"`
+	SyncAvatar         bool `env:"SYNC_AVATAR"`
+}
+
+func (cfg *Config) destination() Destination {
+	return Destination{
+		Name:               "DEST",
+		URL:                cfg.DestURL,
+		Type:               cfg.DestType,
+		Token:              cfg.DestToken,
+		Owner:              cfg.DestOwner,
+		OwnerMap:           cfg.DestOwnerMap,
+		CreateOrgs:         cfg.DestCreateOrgs,
+		MirrorInterval:     cfg.DestMirrorInterval,
+		SyncAll:            cfg.SyncAll,
+		SyncTopics:         cfg.SyncTopics,
+		SyncDescription:    cfg.SyncDescription,
+		SyncWebsite:        cfg.SyncWebsite,
+		SyncWebsiteHTMLURL: cfg.SyncWebsiteHTMLURL,
+		SyncDefaultBranch:  cfg.SyncDefaultBranch,
+		SyncVisibility:     cfg.SyncVisibility,
+		SyncMirrorInterval: cfg.SyncMirrorInterval,
+		SyncArchived:       cfg.SyncArchived,
+		SyncAvatar:         cfg.SyncAvatar,
+	}
+}
+
+func parseDestinations(first Destination) ([]Destination, error) {
+	// Expand SYNC_ALL before it is inherited so other destinations can override the SYNC_* variables
+	first.expandSyncAll()
+
+	dests := []Destination{first}
+	for i := 2; i <= MaximumDestinations; i++ {
+		name := fmt.Sprintf("DEST%d", i)
+		if _, ok := os.LookupEnv(name + "_URL"); !ok {
+			continue
+		}
+
+		dest := first
+		dest.Name = name
+		dest.Token = ""
+		if err := env.Parse(&dest, env.Options{Prefix: name + "_"}); err != nil {
+			return nil, err
+		}
+		dest.expandSyncAll()
+
+		dests = append(dests, dest)
+	}
+
+	for i := range dests {
+		if err := dests[i].parseAndValidate(); err != nil {
+			return nil, err
+		}
+	}
+
+	return dests, nil
+}
+
+// expandSyncAll enables the SYNC_* variables when SYNC_ALL is set and then clears it.
+// SYNC_ARCHIVED and SYNC_AVATAR are left out so they stay opt-in.
+func (dest *Destination) expandSyncAll() {
+	if dest.SyncAll {
+		dest.SyncDefaultBranch = true
+		dest.SyncDescription = true
+		dest.SyncMirrorInterval = true
+		dest.SyncTopics = true
+		dest.SyncVisibility = true
+		dest.SyncWebsite = true
+	}
+	dest.SyncAll = false
+}
+
+func (dest *Destination) parseAndValidate() error {
+	if dest.URL == "" {
+		return fmt.Errorf("%s_URL not set", dest.Name)
+	}
+
+	if dest.Token == "" {
+		return fmt.Errorf("%s_TOKEN not set", dest.Name)
+	}
+
+	switch dest.Type {
+	case "", DestinationTypeGitea, DestinationTypeForgejo:
+	default:
+		return fmt.Errorf("invalid %s_TYPE: %s", dest.Name, dest.Type)
+	}
+
+	dest.OwnerRules = nil
+	for _, rule := range dest.OwnerMap {
+		if rule == "" {
+			continue
+		}
+		ownerRule, err := ParseOwnerRule(rule)
+		if err != nil {
+			return fmt.Errorf("invalid %s_OWNER_MAP: %w", dest.Name, err)
+		}
+		dest.OwnerRules = append(dest.OwnerRules, ownerRule)
+	}
+
+	return nil
+}
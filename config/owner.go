@@ -0,0 +1,67 @@
+package config
+
+import (
+	"fmt"
+	"path"
+	"strings"
+)
+
+// OwnerRule maps source repositories matching Pattern to the destination Owner.
+type OwnerRule struct {
+	Source  Source
+	Pattern string
+	Owner   string
+}
+
+// ParseOwnerRule parses a rule in the form "[source:]owner[/name]=destination" where owner and name are glob patterns (e.g. "github:ourcompany/*=company-mirrors").
+func ParseOwnerRule(rule string) (OwnerRule, error) {
+	pattern, owner, ok := strings.Cut(rule, "=")
+	if !ok || pattern == "" || owner == "" {
+		return OwnerRule{}, fmt.Errorf("invalid owner rule: %s", rule)
+	}
+
+	var source Source
+	if before, after, ok := strings.Cut(pattern, ":"); ok {
+		source = Source(strings.ToLower(before))
+		pattern = after
+	}
+
+	if !strings.Contains(pattern, "/") {
+		pattern += "/*"
+	}
+	pattern = strings.ToLower(pattern)
+
+	if _, err := path.Match(pattern, ""); err != nil {
+		return OwnerRule{}, fmt.Errorf("invalid owner rule: %s: %w", rule, err)
+	}
+
+	return OwnerRule{
+		Source:  source,
+		Pattern: pattern,
+		Owner:   owner,
+	}, nil
+}
+
+func (r OwnerRule) Match(source Source, owner, name string) bool {
+	if r.Source != "" && r.Source != source {
+		return false
+	}
+
+	ok, _ := path.Match(r.Pattern, strings.ToLower(owner+"/"+name))
+	return ok
+}
+
+// MapOwner returns the destination owner of a source repository.
+func (dest *Destination) MapOwner(source Source, owner, name string) string {
+	for _, rule := range dest.OwnerRules {
+		if rule.Match(source, owner, name) {
+			return rule.Owner
+		}
+	}
+
+	if dest.Owner != "" {
+		return dest.Owner
+	}
+
+	return owner
+}
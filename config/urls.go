@@ -0,0 +1,96 @@
+package config
+
+import (
+	"bufio"
+	"fmt"
+	"net/url"
+	"os"
+	"path"
+	"strings"
+)
+
+// URLEntry is a git repository of the urls source.
+type URLEntry struct {
+	Owner string
+	Name  string
+	URL   string
+}
+
+// ParseURLEntry parses an entry in the form "[owner/name=]url" where owner and name are derived from the last two segments of the url's path when they are not given.
+func ParseURLEntry(entry string) (URLEntry, error) {
+	var owner, name string
+	rawURL := entry
+	if before, after, ok := strings.Cut(entry, "="); ok && !strings.Contains(before, ":") {
+		owner, name, ok = strings.Cut(before, "/")
+		if !ok || owner == "" || name == "" || strings.Contains(name, "/") {
+			return URLEntry{}, fmt.Errorf("invalid url entry: %s", entry)
+		}
+		rawURL = after
+	}
+
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return URLEntry{}, fmt.Errorf("invalid url entry: %s: %w", entry, err)
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return URLEntry{}, fmt.Errorf("invalid url entry: %s: only http and https urls are supported", entry)
+	}
+
+	if name == "" {
+		dir, base := path.Split(strings.TrimSuffix(strings.Trim(u.Path, "/"), ".git"))
+		if base == "" {
+			return URLEntry{}, fmt.Errorf("invalid url entry: %s: url has no repository name", entry)
+		}
+
+		owner, name = path.Base(dir), base
+		if dir == "" {
+			owner = u.Hostname()
+		}
+	}
+
+	return URLEntry{
+		Owner: owner,
+		Name:  name,
+		URL:   rawURL,
+	}, nil
+}
+
+// ReadURLEntries reads entries from a file with one entry per line, empty lines and lines starting with "#" are ignored.
+func ReadURLEntries(file string) ([]URLEntry, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var entries []URLEntry
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		entry, err := ParseURLEntry(line)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+
+	return entries, scanner.Err()
+}
+
+// GetURLEntries returns the entries of URLS and URLS_FILE, the file is read on every call so it can change between runs.
+func (cfg *Config) GetURLEntries() ([]URLEntry, error) {
+	entries := cfg.URLEntries
+	if cfg.URLsFile != "" {
+		fileEntries, err := ReadURLEntries(cfg.URLsFile)
+		if err != nil {
+			return nil, fmt.Errorf("could not read URLS_FILE: %w", err)
+		}
+		entries = append(append([]URLEntry{}, entries...), fileEntries...)
+	}
+
+	return entries, nil
+}
@@ -0,0 +1,43 @@
+package config
+
+import "testing"
+
+func TestParseURLEntry(t *testing.T) {
+	tests := []struct {
+		entry string
+		want  URLEntry
+	}{
+		{"https://git.kernel.org/pub/scm/git/git.git", URLEntry{Owner: "git", Name: "git", URL: "https://git.kernel.org/pub/scm/git/git.git"}},
+		{"https://git.zx2c4.com/wireguard-tools", URLEntry{Owner: "git.zx2c4.com", Name: "wireguard-tools", URL: "https://git.zx2c4.com/wireguard-tools"}},
+		{"http://example.com:8080/repo.git/", URLEntry{Owner: "example.com", Name: "repo", URL: "http://example.com:8080/repo.git/"}},
+		{"me/wg=https://git.zx2c4.com/wireguard-tools", URLEntry{Owner: "me", Name: "wg", URL: "https://git.zx2c4.com/wireguard-tools"}},
+		{"https://example.com/owner/repo?ref=main", URLEntry{Owner: "owner", Name: "repo", URL: "https://example.com/owner/repo?ref=main"}},
+		{"me/repo=https://example.com/a?b=c", URLEntry{Owner: "me", Name: "repo", URL: "https://example.com/a?b=c"}},
+	}
+	for _, tt := range tests {
+		got, err := ParseURLEntry(tt.entry)
+		if err != nil {
+			t.Errorf("ParseURLEntry(%q) returned error: %v", tt.entry, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseURLEntry(%q) = %+v, want %+v", tt.entry, got, tt.want)
+		}
+	}
+}
+
+func TestParseURLEntryInvalid(t *testing.T) {
+	for _, entry := range []string{
+		"git@github.com:owner/repo.git",
+		"ssh://example.com/owner/repo",
+		"https://example.com/",
+		"https://example.com/.git",
+		"bad=https://example.com/owner/repo",
+		"me/=https://example.com/owner/repo",
+		"me/a/b=https://example.com/owner/repo",
+	} {
+		if _, err := ParseURLEntry(entry); err == nil {
+			t.Errorf("ParseURLEntry(%q) returned no error", entry)
+		}
+	}
+}
@@ -6,6 +6,7 @@ require (
 	code.gitea.io/sdk/gitea v0.15.1-0.20230403033449-6d1bcd107f2d
 	github.com/caarlos0/env/v7 v7.1.0
 	github.com/google/go-github/v50 v50.2.0
+	github.com/hashicorp/go-version v1.6.0
 	go.uber.org/zap v1.24.0
 	golang.org/x/oauth2 v0.6.0
 )
@@ -17,7 +18,6 @@ require (
 	github.com/go-fed/httpsig v1.1.0 // indirect
 	github.com/golang/protobuf v1.5.2 // indirect
 	github.com/google/go-querystring v1.1.0 // indirect
-	github.com/hashicorp/go-version v1.6.0 // indirect
 	go.uber.org/atomic v1.10.0 // indirect
 	go.uber.org/multierr v1.11.0 // indirect
 	golang.org/x/crypto v0.7.0 // indirect
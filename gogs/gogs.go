@@ -0,0 +1,191 @@
+package gogs
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"code.gitea.io/sdk/gitea"
+	"github.com/ItsNotGoodName/sync-gitea-mirrors/tea"
+)
+
+type User struct {
+	UserName    string `json:"username"`
+	FullName    string `json:"full_name"`
+	AvatarURL   string `json:"avatar_url"`
+	Description string `json:"description"`
+	Website     string `json:"website"`
+}
+
+type Repository struct {
+	Owner         User      `json:"owner"`
+	Name          string    `json:"name"`
+	Description   string    `json:"description"`
+	Website       string    `json:"website"`
+	DefaultBranch string    `json:"default_branch"`
+	Private       bool      `json:"private"`
+	Fork          bool      `json:"fork"`
+	Empty         bool      `json:"empty"`
+	HTMLURL       string    `json:"html_url"`
+	CloneURL      string    `json:"clone_url"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+type Client struct {
+	URL   string
+	Token string
+	HTTP  *http.Client
+}
+
+func NewClient(url, token string) *Client {
+	return &Client{
+		URL:   strings.TrimSuffix(url, "/"),
+		Token: token,
+		HTTP:  http.DefaultClient,
+	}
+}
+
+var errNotFound = errors.New("not found")
+
+func (c *Client) get(path string, v any) error {
+	req, err := http.NewRequest("GET", c.URL+"/api/v1"+path, nil)
+	if err != nil {
+		return err
+	}
+	if c.Token != "" {
+		req.Header.Set("Authorization", "token "+c.Token)
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.HTTP.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return fmt.Errorf("%s: %w", path, errNotFound)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s: %s", path, resp.Status)
+	}
+
+	return json.NewDecoder(resp.Body).Decode(v)
+}
+
+// ListRepos lists the repositories of an organization or user, or every repository the user can access when owner is empty.
+func ListRepos(c *Client, owner string, skipPrivate bool, skipForks bool) ([]Repository, error) {
+	var repos []Repository
+	if owner == "" {
+		if err := c.get("/user/repos", &repos); err != nil {
+			return nil, err
+		}
+	} else {
+		err := c.get(fmt.Sprintf("/orgs/%s/repos", url.PathEscape(owner)), &repos)
+		if errors.Is(err, errNotFound) {
+			err = c.get(fmt.Sprintf("/users/%s/repos", url.PathEscape(owner)), &repos)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Skip private or forks
+	var newRepos []Repository
+	for _, repo := range repos {
+		if skipPrivate && repo.Private {
+			continue
+		}
+
+		if skipForks && repo.Fork {
+			continue
+		}
+
+		newRepos = append(newRepos, repo)
+	}
+
+	return newRepos, nil
+}
+
+func ConvertList(gogsRepos []Repository) []tea.SourceRepository {
+	repos := make([]tea.SourceRepository, len(gogsRepos))
+	for i := range repos {
+		repos[i] = Convert(gogsRepos[i])
+	}
+	return repos
+}
+
+func Convert(r Repository) tea.SourceRepository {
+	return tea.SourceRepository{
+		SyncRepository: tea.SyncRepository{
+			Description:   r.Description,
+			Website:       r.Website,
+			DefaultBranch: r.DefaultBranch,
+			Private:       r.Private,
+			Empty:         gitea.OptionalBool(r.Empty),
+			PushedAt:      r.UpdatedAt,
+		},
+		Owner:     r.Owner.UserName,
+		Name:      r.Name,
+		Fork:      r.Fork,
+		HTMLURL:   r.HTMLURL,
+		AvatarURL: r.Owner.AvatarURL,
+		URLS:      []string{r.CloneURL, r.HTMLURL},
+	}
+}
+
+func ListRefs(c *Client, owner, repoName string) (tea.Refs, error) {
+	path := fmt.Sprintf("/repos/%s/%s", url.PathEscape(owner), url.PathEscape(repoName))
+	refs := make(tea.Refs)
+
+	// Branches and tags both return their commit as a PayloadCommit
+	type ref struct {
+		Name   string `json:"name"`
+		Commit struct {
+			ID string `json:"id"`
+		} `json:"commit"`
+	}
+
+	var branches []ref
+	if err := c.get(path+"/branches", &branches); err != nil {
+		return nil, err
+	}
+	for _, b := range branches {
+		refs["refs/heads/"+b.Name] = b.Commit.ID
+	}
+
+	var tags []ref
+	// Older versions of Gogs do not have the tags API
+	if err := c.get(path+"/tags", &tags); err != nil && !errors.Is(err, errNotFound) {
+		return nil, err
+	}
+	for _, t := range tags {
+		refs["refs/tags/"+t.Name] = t.Commit.ID
+	}
+
+	return refs, nil
+}
+
+func GetOwner(c *Client, name string) (tea.SourceOwner, error) {
+	var u User
+	err := c.get(fmt.Sprintf("/orgs/%s", url.PathEscape(name)), &u)
+	if errors.Is(err, errNotFound) {
+		err = c.get(fmt.Sprintf("/users/%s", url.PathEscape(name)), &u)
+	}
+	if err != nil {
+		return tea.SourceOwner{}, err
+	}
+
+	return tea.SourceOwner{
+		Name:        u.UserName,
+		FullName:    u.FullName,
+		Description: u.Description,
+		Website:     u.Website,
+		AvatarURL:   u.AvatarURL,
+		Visibility:  gitea.VisibleTypePublic,
+	}, nil
+}
@@ -0,0 +1,102 @@
+package hub
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"text/template"
+
+	"code.gitea.io/sdk/gitea"
+	"github.com/ItsNotGoodName/sync-gitea-mirrors/tea"
+	"github.com/google/go-github/v50/github"
+)
+
+// GistName is the data of the template for the name of gists.
+type GistName struct {
+	ID          string
+	Owner       string
+	Description string
+	// Filename is the first file of the gist in alphabetical order.
+	Filename string
+}
+
+// ListGists lists the gists of the users, or the gists of the authenticated user including secret gists when there are no users.
+func ListGists(ctx context.Context, client *github.Client, users []string, skipPrivate bool) ([]*github.Gist, error) {
+	if len(users) == 0 {
+		users = []string{""}
+	}
+
+	var gists []*github.Gist
+	for _, user := range users {
+		page := 1
+		limit := 100
+		for page != 0 {
+			pagedGists, resp, err := client.Gists.List(ctx, user, &github.GistListOptions{
+				ListOptions: github.ListOptions{Page: page, PerPage: limit},
+			})
+			if err != nil {
+				return nil, err
+			}
+			for _, g := range pagedGists {
+				if skipPrivate && !g.GetPublic() {
+					continue
+				}
+
+				gists = append(gists, g)
+			}
+			page = resp.NextPage
+		}
+	}
+
+	return gists, nil
+}
+
+func ConvertGistList(gists []*github.Gist, name *template.Template) ([]tea.SourceRepository, error) {
+	repos := make([]tea.SourceRepository, len(gists))
+	for i, g := range gists {
+		var err error
+		if repos[i], err = ConvertGist(g, name); err != nil {
+			return nil, err
+		}
+	}
+	return repos, nil
+}
+
+// ConvertGist converts a gist to a repository that is migrated as a plain git repository.
+func ConvertGist(g *github.Gist, name *template.Template) (tea.SourceRepository, error) {
+	var filenames []string
+	for filename := range g.Files {
+		filenames = append(filenames, string(filename))
+	}
+	sort.Strings(filenames)
+
+	data := GistName{
+		ID:          g.GetID(),
+		Owner:       g.GetOwner().GetLogin(),
+		Description: g.GetDescription(),
+	}
+	if len(filenames) > 0 {
+		data.Filename = filenames[0]
+	}
+
+	var b strings.Builder
+	if err := name.Execute(&b, data); err != nil {
+		return tea.SourceRepository{}, err
+	}
+
+	return tea.SourceRepository{
+		SyncRepository: tea.SyncRepository{
+			Description: g.GetDescription(),
+			Private:     !g.GetPublic(),
+			// Gists always have a file
+			Empty:    gitea.OptionalBool(false),
+			PushedAt: g.GetUpdatedAt().Time,
+		},
+		Owner:     data.Owner,
+		Name:      b.String(),
+		HTMLURL:   g.GetHTMLURL(),
+		AvatarURL: g.GetOwner().GetAvatarURL(),
+		URLS:      []string{g.GetGitPullURL(), g.GetHTMLURL()},
+		Plain:     true,
+	}, nil
+}
@@ -2,12 +2,18 @@ package hub
 
 import (
 	"context"
+	"net/http"
+	"strings"
 
+	"code.gitea.io/sdk/gitea"
 	"github.com/ItsNotGoodName/sync-gitea-mirrors/tea"
 	"github.com/google/go-github/v50/github"
 	"golang.org/x/oauth2"
 )
 
+// GitUsername is used for git authentication with a token.
+const GitUsername = "x-access-token"
+
 func ConvertList(hubRepos []*github.Repository) []tea.SourceRepository {
 	repos := make([]tea.SourceRepository, len(hubRepos))
 	for i := range repos {
@@ -17,22 +23,207 @@ func ConvertList(hubRepos []*github.Repository) []tea.SourceRepository {
 }
 
 func Convert(r *github.Repository) tea.SourceRepository {
+	// GitBucket does not have the size of repositories
+	var empty *bool
+	if r.Size != nil {
+		empty = gitea.OptionalBool(r.GetSize() == 0)
+	}
+
 	return tea.SourceRepository{
 		SyncRepository: tea.SyncRepository{
-			Topics:      r.Topics,
-			Description: r.GetDescription(),
-			Private:     r.GetPrivate(),
-			Archived:    r.GetArchived(),
-			PushedAt:    r.GetPushedAt().Time,
+			Topics:        r.Topics,
+			Description:   r.GetDescription(),
+			Website:       r.GetHomepage(),
+			DefaultBranch: r.GetDefaultBranch(),
+			Private:       r.GetPrivate(),
+			Archived:      r.GetArchived(),
+			Empty:         empty,
+			PushedAt:      r.GetPushedAt().Time,
 		},
-		Owner: r.GetOwner().GetLogin(),
-		Name:  r.GetName(),
-		Fork:  r.GetFork(),
-		URLS:  []string{r.GetCloneURL(), r.GetHTMLURL()},
+		Owner:     r.GetOwner().GetLogin(),
+		Name:      r.GetName(),
+		Fork:      r.GetFork(),
+		HTMLURL:   r.GetHTMLURL(),
+		AvatarURL: r.GetOwner().GetAvatarURL(),
+		URLS:      []string{r.GetCloneURL(), r.GetHTMLURL()},
+	}
+}
+
+func GetOwner(ctx context.Context, client *github.Client, login string) (tea.SourceOwner, error) {
+	u, _, err := client.Users.Get(ctx, login)
+	if err != nil {
+		return tea.SourceOwner{}, err
+	}
+
+	owner := tea.SourceOwner{
+		Name:        u.GetLogin(),
+		FullName:    u.GetName(),
+		Description: u.GetBio(),
+		Website:     u.GetBlog(),
+		AvatarURL:   u.GetAvatarURL(),
+	}
+
+	if u.GetType() == "Organization" {
+		org, _, err := client.Organizations.Get(ctx, login)
+		if err != nil {
+			return tea.SourceOwner{}, err
+		}
+
+		owner.Description = org.GetDescription()
+	}
+
+	return owner, nil
+}
+
+// ListRepos lists the repositories of the owners, or the repositories of the authenticated user with the affiliation when there are no owners.
+// Organizations include private repositories the user can access while other users only have public repositories.
+func ListRepos(ctx context.Context, client *github.Client, owners []string, affiliation string, skipPrivate bool, skipForks bool) ([]*github.Repository, error) {
+	var repos []*github.Repository
+	if len(owners) == 0 {
+		var err error
+		repos, err = listAuthenticatedRepos(ctx, client, affiliation, skipPrivate)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		var login string
+		seen := make(map[int64]bool)
+		for _, owner := range owners {
+			u, _, err := client.Users.Get(ctx, owner)
+			if err != nil {
+				return nil, err
+			}
+
+			var ownerRepos []*github.Repository
+			if u.GetType() == "Organization" {
+				ownerRepos, err = listOrgRepos(ctx, client, owner, skipPrivate)
+			} else {
+				if login == "" {
+					me, _, err := client.Users.Get(ctx, "")
+					if err != nil {
+						return nil, err
+					}
+					login = me.GetLogin()
+				}
+
+				if strings.EqualFold(u.GetLogin(), login) {
+					ownerRepos, err = listAuthenticatedRepos(ctx, client, "owner", skipPrivate)
+				} else {
+					ownerRepos, err = listUserRepos(ctx, client, owner)
+				}
+			}
+			if err != nil {
+				return nil, err
+			}
+
+			for _, r := range ownerRepos {
+				if !seen[r.GetID()] {
+					seen[r.GetID()] = true
+					repos = append(repos, r)
+				}
+			}
+		}
+	}
+
+	// Skip private or forks
+	var newRepos []*github.Repository
+	for _, r := range repos {
+		if skipPrivate && r.GetPrivate() {
+			continue
+		}
+
+		if skipForks && r.GetFork() {
+			continue
+		}
+
+		newRepos = append(newRepos, r)
+	}
+
+	return newRepos, nil
+}
+
+// ListStarred lists the repositories starred by the users, or by the authenticated user when there are no users.
+func ListStarred(ctx context.Context, client *github.Client, users []string, skipPrivate bool, skipForks bool) ([]*github.Repository, error) {
+	if len(users) == 0 {
+		users = []string{""}
+	}
+
+	var repos []*github.Repository
+	seen := make(map[int64]bool)
+	for _, user := range users {
+		page := 1
+		limit := 100
+		for page != 0 {
+			starred, resp, err := client.Activity.ListStarred(ctx, user, &github.ActivityListStarredOptions{
+				Sort:        "created",
+				ListOptions: github.ListOptions{Page: page, PerPage: limit},
+			})
+			if err != nil {
+				return nil, err
+			}
+			for _, s := range starred {
+				r := s.GetRepository()
+				if seen[r.GetID()] {
+					continue
+				}
+				seen[r.GetID()] = true
+
+				if skipPrivate && r.GetPrivate() {
+					continue
+				}
+
+				if skipForks && r.GetFork() {
+					continue
+				}
+
+				repos = append(repos, r)
+			}
+			page = resp.NextPage
+		}
+	}
+
+	return repos, nil
+}
+
+const SearchLimit = 1000
+
+// SearchRepos lists the repositories matching the query (e.g. "org:kubernetes topic:operator archived:false").
+// The search API only returns the first SearchLimit results of the total.
+func SearchRepos(ctx context.Context, client *github.Client, query string, skipPrivate bool, skipForks bool) ([]*github.Repository, int, error) {
+	var repos []*github.Repository
+	var total int
+	page := 1
+	limit := 100
+	for page != 0 {
+		result, resp, err := client.Search.Repositories(ctx, query, &github.SearchOptions{
+			ListOptions: github.ListOptions{Page: page, PerPage: limit},
+		})
+		if err != nil {
+			return nil, 0, err
+		}
+		total = result.GetTotal()
+		for _, r := range result.Repositories {
+			if skipPrivate && r.GetPrivate() {
+				continue
+			}
+
+			if skipForks && r.GetFork() {
+				continue
+			}
+
+			repos = append(repos, r)
+		}
+		page = resp.NextPage
+		if page*limit > SearchLimit {
+			// Pages after the limit return an error
+			break
+		}
 	}
+
+	return repos, total, nil
 }
 
-func ListRepos(ctx context.Context, client *github.Client, owner string, skipPrivate bool, skipForks bool) ([]*github.Repository, error) {
+func listAuthenticatedRepos(ctx context.Context, client *github.Client, affiliation string, skipPrivate bool) ([]*github.Repository, error) {
 	visiblity := "all"
 	if skipPrivate {
 		visiblity = "public"
@@ -41,10 +232,11 @@ func ListRepos(ctx context.Context, client *github.Client, owner string, skipPri
 	page := 1
 	limit := 100
 	for page != 0 {
-		pagedRepos, resp, err := client.Repositories.List(ctx, owner,
+		pagedRepos, resp, err := client.Repositories.List(ctx, "",
 			&github.RepositoryListOptions{
 				Sort:        "created",
 				Visibility:  visiblity,
+				Affiliation: affiliation,
 				ListOptions: github.ListOptions{Page: page, PerPage: limit},
 			})
 		if err != nil {
@@ -54,28 +246,109 @@ func ListRepos(ctx context.Context, client *github.Client, owner string, skipPri
 		page = resp.NextPage
 	}
 
-	if skipForks {
-		var notForkRepos []*github.Repository
-		for _, r := range repos {
-			if !r.GetFork() {
-				notForkRepos = append(notForkRepos, r)
-			}
+	return repos, nil
+}
+
+func listOrgRepos(ctx context.Context, client *github.Client, org string, skipPrivate bool) ([]*github.Repository, error) {
+	typ := "all"
+	if skipPrivate {
+		typ = "public"
+	}
+	var repos []*github.Repository
+	page := 1
+	limit := 100
+	for page != 0 {
+		pagedRepos, resp, err := client.Repositories.ListByOrg(ctx, org,
+			&github.RepositoryListByOrgOptions{
+				Type:        typ,
+				Sort:        "created",
+				ListOptions: github.ListOptions{Page: page, PerPage: limit},
+			})
+		if err != nil {
+			return nil, err
+		}
+		repos = append(repos, pagedRepos...)
+		page = resp.NextPage
+	}
+
+	return repos, nil
+}
+
+func listUserRepos(ctx context.Context, client *github.Client, user string) ([]*github.Repository, error) {
+	var repos []*github.Repository
+	page := 1
+	limit := 100
+	for page != 0 {
+		pagedRepos, resp, err := client.Repositories.List(ctx, user,
+			&github.RepositoryListOptions{
+				Type:        "owner",
+				Sort:        "created",
+				ListOptions: github.ListOptions{Page: page, PerPage: limit},
+			})
+		if err != nil {
+			return nil, err
 		}
-		repos = notForkRepos
+		repos = append(repos, pagedRepos...)
+		page = resp.NextPage
 	}
 
 	return repos, nil
 }
 
+func ListRefs(ctx context.Context, client *github.Client, owner, repoName string) (tea.Refs, error) {
+	refs := make(tea.Refs)
+
+	page := 1
+	for page != 0 {
+		branches, resp, err := client.Repositories.ListBranches(ctx, owner, repoName, &github.BranchListOptions{
+			ListOptions: github.ListOptions{Page: page, PerPage: 100},
+		})
+		if err != nil {
+			return nil, err
+		}
+		for _, b := range branches {
+			refs["refs/heads/"+b.GetName()] = b.GetCommit().GetSHA()
+		}
+		page = resp.NextPage
+	}
+
+	page = 1
+	for page != 0 {
+		tags, resp, err := client.Repositories.ListTags(ctx, owner, repoName, &github.ListOptions{Page: page, PerPage: 100})
+		if err != nil {
+			return nil, err
+		}
+		for _, t := range tags {
+			refs["refs/tags/"+t.GetName()] = t.GetCommit().GetSHA()
+		}
+		page = resp.NextPage
+	}
+
+	return refs, nil
+}
+
 func NewClient(ctx context.Context, token string) *github.Client {
+	return github.NewClient(newHTTPClient(ctx, token))
+}
+
+// NewEnterpriseClient creates a client for GitHub Enterprise Server or a server implementing the GitHub API at /api/v3 (e.g. GitBucket).
+// The upload URL is the base URL when empty.
+func NewEnterpriseClient(ctx context.Context, baseURL, uploadURL, token string) (*github.Client, error) {
+	if uploadURL == "" {
+		uploadURL = baseURL
+	}
+
+	return github.NewEnterpriseClient(baseURL, uploadURL, newHTTPClient(ctx, token))
+}
+
+func newHTTPClient(ctx context.Context, token string) *http.Client {
 	if token == "" {
-		return github.NewClient(nil)
+		return nil
 	}
 
 	ts := oauth2.StaticTokenSource(
 		&oauth2.Token{AccessToken: token},
 	)
-	tc := oauth2.NewClient(ctx, ts)
 
-	return github.NewClient(tc)
+	return oauth2.NewClient(ctx, ts)
 }
@@ -2,18 +2,30 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"strings"
 	"time"
 
+	"github.com/ItsNotGoodName/sync-gitea-mirrors/azure"
+	"github.com/ItsNotGoodName/sync-gitea-mirrors/bucket"
 	"github.com/ItsNotGoodName/sync-gitea-mirrors/config"
+	"github.com/ItsNotGoodName/sync-gitea-mirrors/gogs"
 	"github.com/ItsNotGoodName/sync-gitea-mirrors/hub"
+	"github.com/ItsNotGoodName/sync-gitea-mirrors/onedev"
+	"github.com/ItsNotGoodName/sync-gitea-mirrors/remote"
+	"github.com/ItsNotGoodName/sync-gitea-mirrors/srht"
 	"github.com/ItsNotGoodName/sync-gitea-mirrors/tea"
+	"github.com/google/go-github/v50/github"
 	"go.uber.org/zap"
 
 	"code.gitea.io/sdk/gitea"
 )
 
 var log *zap.Logger
+
+// brokenSyncs records when broken mirrors were synced by the health check so they are only repaired by a later run.
+var brokenSyncs = make(map[string]time.Time)
 var (
 	version = "dev"
 	commit  = "none"
@@ -44,25 +56,25 @@ func main() {
 		log.Fatal("could not parse config", zap.Error(err))
 	}
 
-	if cfg.Source == config.SourceGitHub {
-		if cfg.GitHubOwner != "" {
-			log.Warn("setting GITHUB_OWNER will only show public repositories")
+	syncConfigs := make([]tea.SyncConfig, len(cfg.Destinations))
+	for i, dest := range cfg.Destinations {
+		syncConfigs[i] = tea.SyncConfig{
+			SyncDescription:    dest.SyncDescription,
+			SyncWebsite:        dest.SyncWebsite,
+			SyncDefaultBranch:  dest.SyncDefaultBranch,
+			SyncMirrorInterval: dest.SyncMirrorInterval,
+			SyncTopics:         dest.SyncTopics,
+			SyncVisibility:     dest.SyncVisibility,
+			SyncArchived:       dest.SyncArchived,
+			DestMirrorInterval: dest.MirrorInterval,
 		}
-	}
 
-	syncConfig := tea.SyncConfig{
-		SyncDescription:    cfg.SyncDescription,
-		SyncMirrorInterval: cfg.SyncMirrorInterval,
-		SyncTopics:         cfg.SyncTopics,
-		SyncVisibility:     cfg.SyncVisibility,
-		DestMirrorInterval: cfg.DestMirrorInterval,
+		fmt.Printf("%s: SyncConfig: %+v\n", dest.URL, syncConfigs[i])
 	}
 
-	fmt.Printf("SyncConfig: %+v\n", syncConfig)
-
 	if cfg.Daemon == 0 {
 		// Normal
-		if err := run(cfg, &syncConfig); err != nil {
+		if err := run(cfg, syncConfigs); err != nil {
 			log.Fatal("main", zap.Error(err))
 		}
 	} else {
@@ -76,7 +88,7 @@ func main() {
 		}
 
 		for {
-			if err := run(cfg, &syncConfig); err != nil {
+			if err := run(cfg, syncConfigs); err != nil {
 				if cfg.DaemonExitError {
 					log.Fatal("main", zap.Error(err))
 				}
@@ -93,25 +105,97 @@ func main() {
 	}
 }
 
-func run(cfg *config.Config, syncConfig *tea.SyncConfig) error {
+func run(cfg *config.Config, syncConfigs []tea.SyncConfig) error {
+	// Get repositories based config
+	src, err := getSource(cfg)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Will sync %d repositories\n", len(src.repos))
+
+	var reterr error
+	for i := range cfg.Destinations {
+		dest := &cfg.Destinations[i]
+		if len(cfg.Destinations) > 1 {
+			fmt.Println("Syncing to", dest.URL)
+		}
+
+		if err := syncDestination(cfg, dest, &syncConfigs[i], &src); err != nil {
+			reterr = errors.Join(reterr, fmt.Errorf("%s: %w", dest.URL, err))
+		}
+	}
+
+	return reterr
+}
+
+func syncDestination(cfg *config.Config, dest *config.Destination, syncConfig *tea.SyncConfig, src *source) error {
 	// Create client
-	client, err := gitea.NewClient(cfg.DestURL, gitea.SetToken(cfg.DestToken))
+	client, err := gitea.NewClient(dest.URL, gitea.SetToken(dest.Token))
 	if err != nil {
 		return fmt.Errorf("could not create destination Gitea client: %w", err)
 	}
 
-	// Get repositories based config
-	repos, migrateRepoOption, err := getSourceRepos(cfg)
+	// Detect server
+	server, err := tea.GetServer(client, dest.URL)
 	if err != nil {
-		return err
+		return fmt.Errorf("could not get destination server: %w", err)
+	}
+	if dest.Type != "" && string(dest.Type) != string(server.Flavor) {
+		log.Warn("destination is not the configured type", zap.String("url", dest.URL), zap.String("type", string(dest.Type)), zap.String("detected", string(server.Flavor)))
 	}
+	fmt.Printf("Destination is %s %s\n", server.Flavor, server.Version)
 
-	fmt.Printf("Will sync %d repositories\n", len(repos))
+	// Check capabilities
+	caps := server.Capabilities()
+	fmt.Printf("Capabilities: %+v\n", caps)
+	if !caps.Mirrors {
+		return fmt.Errorf("mirrors are disabled on the destination (DISABLE_MIRRORS in app.ini)")
+	}
+	if !caps.Migrations {
+		return fmt.Errorf("migrations are disabled on the destination (DISABLE_MIGRATIONS in app.ini)")
+	}
+
+	migrateRepoOption := src.migrateRepoOption
+	migrateRepoOption.Service = server.MigrationService(migrateRepoOption.Service, src.flavor)
+	migrateWait := time.Duration(cfg.MigrateWait) * time.Second
+	migrateTimeout := time.Duration(cfg.MigrateTimeout) * time.Second
+	healthStale := time.Duration(cfg.HealthStale) * time.Second
+	migrateLFS := cfg.MigrateLFS
+	if migrateLFS && !caps.LFS {
+		log.Warn("destination does not support migrating lfs", zap.String("url", dest.URL))
+		migrateLFS = false
+	}
+
+	destSyncConfig := *syncConfig
+	syncConfig = &destSyncConfig
+	if syncConfig.SyncMirrorInterval && !caps.MirrorInterval {
+		log.Warn("destination does not support editing mirror-interval", zap.String("url", dest.URL))
+		syncConfig.SyncMirrorInterval = false
+	}
+	if syncConfig.SyncTopics && !caps.Topics {
+		log.Warn("destination does not support topics", zap.String("url", dest.URL))
+		syncConfig.SyncTopics = false
+	}
+	syncAvatar := dest.SyncAvatar
+	if syncAvatar && !caps.Avatars {
+		log.Warn("destination does not support avatars", zap.String("url", dest.URL))
+		syncAvatar = false
+	}
+	verify := cfg.Verify || src.verify
+	if verify && src.getRefs == nil {
+		log.Warn("source does not support verifying refs", zap.String("source", string(cfg.Source)))
+		verify = false
+	}
 
 	syncingError := false
+	createdOrgs := make(map[string]bool)
+	claimedRepos := make(map[string]string)
+	var conflicts []string
+	verifiedCount, driftedCount := 0, 0
 
 Loop:
-	for _, repo := range repos {
+	for _, repo := range src.repos {
 		// Skip
 		for _, skipRepo := range cfg.SkipRepos {
 			if repo.Is(skipRepo) {
@@ -120,46 +204,198 @@ Loop:
 			}
 		}
 
+		// Destination description
+		if cfg.Description != nil {
+			description, err := repo.Execute(cfg.Description)
+			if err != nil {
+				log.Error("could not execute description template", zap.String("repo", repo.GetFullName()), zap.Error(err))
+				syncingError = true
+				continue
+			}
+			repo.Description = description
+		}
+
+		// Destination website
+		if dest.SyncWebsiteHTMLURL {
+			repo.Website = repo.HTMLURL
+		}
+
 		// Destination repo name and owner
-		owner := cfg.DestOwner
+		owner := dest.MapOwner(cfg.Source, repo.Owner, repo.Name)
 		if owner == "" {
-			owner = repo.Owner
+			log.Error("no destination owner for repo, set DEST_OWNER or DEST_OWNER_MAP", zap.String("repo", repo.GetFullName()))
+			syncingError = true
+			continue
 		}
 		name := repo.Name
+		if cfg.DestName != nil {
+			if name, err = repo.Execute(cfg.DestName); err != nil {
+				log.Error("could not execute name template", zap.String("repo", repo.GetFullName()), zap.Error(err))
+				syncingError = true
+				continue
+			}
+		}
 
-		teaRepo, err := tea.GetRepoOrNil(client, owner, name)
+		teaRepo, name, repoConflicts, err := getDestRepo(cfg, client, claimedRepos, &repo, owner, name)
 		if err != nil {
 			log.Error("could not get destination repo", zap.String("owner", owner), zap.String("name", name), zap.Error(err))
 			syncingError = true
 			continue
 		}
+		if name == "" {
+			if cfg.DestCollision == config.CollisionFail {
+				return fmt.Errorf("could not find destination for %s: %s", repo.GetFullName(), strings.Join(repoConflicts, ", "))
+			}
+
+			fmt.Println("Skipping", repo.GetFullName(), "due to conflict")
+			conflicts = append(conflicts, repoConflicts...)
+			continue
+		}
+
+		// Handle unfinished migrations
+		if teaRepo != nil && caps.AsyncMigrations && !tea.MigrationDone(teaRepo) {
+			if time.Since(teaRepo.Created) < migrateTimeout {
+				fmt.Println("Skipping", repo.GetFullName(), "migration is still running")
+				continue
+			}
+
+			fmt.Println("Deleting", teaRepo.FullName, "migration failed")
+			if _, err := client.DeleteRepo(owner, name); err != nil {
+				log.Error("could not delete failed migration", zap.String("owner", owner), zap.String("name", name), zap.Error(err))
+				syncingError = true
+				continue
+			}
+			teaRepo = nil
+		}
+
+		// Check health of existing repo
+		mirrorInterval := ""
+		brokenKey, brokenSync := "", false
+		if teaRepo != nil && cfg.HealthCheck {
+			brokenKey = strings.ToLower(dest.URL + "/" + teaRepo.FullName)
+			_, synced := brokenSyncs[brokenKey]
+			if reason := repo.Broken(teaRepo, healthStale); reason == "" {
+				delete(brokenSyncs, brokenKey)
+			} else if !synced {
+				// Try a mirror sync first, the mirror is only broken when a later run finds it still broken
+				fmt.Println("Broken", teaRepo.FullName, reason)
+				brokenSync = true
+			} else if !cfg.HealthRepair {
+				log.Warn("broken mirror", zap.String("owner", owner), zap.String("name", name), zap.String("reason", reason))
+			} else {
+				fmt.Println("Deleting", teaRepo.FullName, reason, "despite syncing")
+				if _, err := client.DeleteRepo(owner, name); err != nil {
+					log.Error("could not delete broken mirror", zap.String("owner", owner), zap.String("name", name), zap.Error(err))
+					syncingError = true
+					continue
+				}
+				delete(brokenSyncs, brokenKey)
+				mirrorInterval = teaRepo.MirrorInterval
+				teaRepo = nil
+			}
+		}
 
 		// Migrate new repo
+		migrated := teaRepo == nil
 		if teaRepo == nil {
+			// Create destination org
+			if dest.CreateOrgs && !createdOrgs[strings.ToLower(owner)] {
+				// Mapped owners are a different org, so they do not copy the source owner
+				var sourceOwner *tea.SourceOwner
+				if src.getOwner != nil && strings.EqualFold(owner, repo.Owner) {
+					if o, err := src.getOwner(repo.Owner); err != nil {
+						log.Warn("could not get source owner", zap.String("owner", repo.Owner), zap.Error(err))
+					} else {
+						if !caps.Avatars {
+							o.AvatarURL = ""
+						}
+						sourceOwner = &o
+					}
+				}
+
+				created, err := tea.CreateOrgIfNotExist(client, dest.URL, dest.Token, owner, sourceOwner)
+				if err != nil {
+					log.Error("could not create destination org", zap.String("owner", owner), zap.Error(err))
+					syncingError = true
+					if !created {
+						continue
+					}
+				}
+				if created {
+					fmt.Println("Created org", owner)
+				}
+				createdOrgs[strings.ToLower(owner)] = true
+			}
+
 			fmt.Println("Migrating", repo.GetFullName())
 
 			opts := migrateRepoOption
+			if repo.Plain {
+				opts.Service = gitea.GitServicePlain
+				if opts.AuthToken != "" {
+					// Plain git migrations only use the username and password
+					opts.AuthUsername = hub.GitUsername
+					opts.AuthPassword = opts.AuthToken
+					opts.AuthToken = ""
+				}
+			}
 			opts.Mirror = true
 			opts.RepoOwner = owner
 			opts.RepoName = name
 			opts.CloneAddr = repo.URLS[0]
 			opts.Private = repo.Private
 			opts.Wiki = cfg.MigrateWiki
-			opts.LFS = cfg.MigrateLFS
+			opts.LFS = migrateLFS
+			opts.MirrorInterval = mirrorInterval
+			if cfg.Description != nil {
+				opts.Description = tea.MigrateDescription(repo.Description)
+			}
 
-			if teaRepo, _, err = client.MigrateRepo(opts); err != nil {
+			if !caps.AsyncMigrations {
+				teaRepo, _, err = client.MigrateRepo(opts)
+			} else {
+				teaRepo, err = tea.Migrate(client, opts, migrateWait, cfg.MigrateRetries)
+			}
+			if errors.Is(err, tea.ErrMigrationRunning) {
+				fmt.Println("Skipping", repo.GetFullName(), "migration is still running")
+				continue
+			}
+			if err != nil {
 				log.Error("could not migrate repo", zap.String("owner", owner), zap.String("name", name), zap.Error(err))
 				syncingError = true
 				continue
 			}
-		} else if !repo.IsMyMirror(teaRepo) {
-			fmt.Println("Skipping", repo.GetFullName(), "does not belong to mirror", teaRepo.FullName)
-			continue
+		}
+
+		// Verify refs of existing repo
+		drifted := false
+		if verify && !migrated {
+			drift, err := verifyRefs(client, src, &repo, owner, name)
+			if err != nil {
+				log.Error("could not verify repo", zap.String("owner", owner), zap.String("name", name), zap.Error(err))
+				syncingError = true
+			} else {
+				verifiedCount++
+				if drifted = drift.Drifted(); drifted {
+					driftedCount++
+				} else {
+					fmt.Println("~ Verified refs")
+				}
+				for _, ref := range drift.Missing {
+					fmt.Println("! Missing", ref)
+				}
+				for _, ref := range drift.Different {
+					fmt.Println("! Different", ref)
+				}
+			}
 		}
 
 		// Sync existing repo
 		fmt.Println("Syncing", repo.GetFullName())
-		output, err := tea.Sync(client, teaRepo, &repo.SyncRepository, syncConfig)
+		output, err := tea.Sync(client, teaRepo, &repo.SyncRepository, syncConfig, brokenSync || drifted)
+		if brokenSync && output.SyncMirror {
+			brokenSyncs[brokenKey] = time.Now()
+		}
 		if err != nil {
 			log.Error("could not sync repo", zap.String("owner", owner), zap.String("name", name), zap.Error(err))
 			syncingError = true
@@ -169,9 +405,18 @@ Loop:
 		if output.SyncMirror {
 			fmt.Println("~ Synced mirror")
 		}
+		if output.SkipSyncMirror {
+			fmt.Println("~ Skipped mirror sync of archived repository")
+		}
 		if output.UpdateDescription {
 			fmt.Println("~ Updated description")
 		}
+		if output.UpdateWebsite {
+			fmt.Println("~ Updated website")
+		}
+		if output.UpdateDefaultBranch {
+			fmt.Println("~ Updated default branch")
+		}
 		if output.UpdateMirrorInterval {
 			fmt.Println("~ Updated mirror-interval")
 		}
@@ -181,6 +426,31 @@ Loop:
 		if output.UpdateVisibility {
 			fmt.Println("~ Updated visibility")
 		}
+		if output.UpdateArchived {
+			fmt.Println("~ Updated archived")
+		}
+
+		// Sync avatar
+		if syncAvatar && repo.AvatarURL != "" {
+			updated, err := tea.SyncAvatar(dest.URL, dest.Token, teaRepo, repo.AvatarURL)
+			if err != nil {
+				log.Error("could not sync repo avatar", zap.String("owner", owner), zap.String("name", name), zap.Error(err))
+				syncingError = true
+			} else if updated {
+				fmt.Println("~ Updated avatar")
+			}
+		}
+	}
+
+	if verify {
+		fmt.Printf("Verified %d repositories, %d drifted\n", verifiedCount, driftedCount)
+	}
+
+	if len(conflicts) > 0 {
+		fmt.Printf("Skipped %d conflicts\n", len(conflicts))
+		for _, conflict := range conflicts {
+			fmt.Println("!", conflict)
+		}
 	}
 
 	if syncingError {
@@ -190,62 +460,392 @@ Loop:
 	return nil
 }
 
-func getSourceRepos(cfg *config.Config) ([]tea.SourceRepository, gitea.MigrateRepoOption, error) {
+func verifyRefs(client *gitea.Client, src *source, repo *tea.SourceRepository, owner, name string) (tea.RefsDrift, error) {
+	sourceRefs, err := src.getRefs(repo)
+	if err != nil {
+		return tea.RefsDrift{}, fmt.Errorf("could not list source refs: %w", err)
+	}
+
+	destRefs, err := tea.ListRefs(client, owner, name)
+	if err != nil {
+		return tea.RefsDrift{}, fmt.Errorf("could not list destination refs: %w", err)
+	}
+
+	return tea.DiffRefs(sourceRefs, destRefs), nil
+}
+
+// getDestRepo returns the destination repository and name of repo based on the collision strategy.
+// A nil repository means it has to be migrated and an empty name means there was no name without a conflict.
+func getDestRepo(cfg *config.Config, client *gitea.Client, claimedRepos map[string]string, repo *tea.SourceRepository, owner, name string) (*gitea.Repository, string, []string, error) {
+	names := []string{name}
+	if cfg.DestCollision == config.CollisionSuffix {
+		names = append(names, name+"-"+repo.Owner)
+	}
+
+	var conflicts []string
+	for _, name := range names {
+		fullName := owner + "/" + name
+
+		if claimer, ok := claimedRepos[strings.ToLower(fullName)]; ok {
+			conflicts = append(conflicts, fmt.Sprintf("%s: %s is already used by %s", repo.GetFullName(), fullName, claimer))
+			continue
+		}
+
+		teaRepo, err := tea.GetRepoOrNil(client, owner, name)
+		if err != nil {
+			return nil, name, nil, err
+		}
+
+		if teaRepo != nil && !repo.IsMyMirror(teaRepo) {
+			if repo.CanAdopt(teaRepo, cfg.DestAdoptHosts) {
+				// The API can not change the remote of a mirror
+				fmt.Println("Adopting", teaRepo.FullName, "mirror of", teaRepo.OriginalURL)
+				log.Warn("adopted mirror still pulls from its old origin, change the clone address in its mirror settings", zap.String("repo", teaRepo.FullName), zap.String("origin", teaRepo.OriginalURL), zap.String("source", repo.URLS[0]))
+			} else {
+				conflicts = append(conflicts, fmt.Sprintf("%s: %s does not belong to mirror", repo.GetFullName(), teaRepo.FullName))
+				continue
+			}
+		}
+
+		claimedRepos[strings.ToLower(fullName)] = repo.GetFullName()
+		return teaRepo, name, nil, nil
+	}
+
+	return nil, "", conflicts, nil
+}
+
+type source struct {
+	repos             []tea.SourceRepository
+	flavor            tea.Flavor
+	migrateRepoOption gitea.MigrateRepoOption
+	getOwner          func(name string) (tea.SourceOwner, error)
+	getRefs           func(repo *tea.SourceRepository) (tea.Refs, error)
+	// verify compares refs of every mirror because the source has no push dates.
+	verify bool
+}
+
+func getSource(cfg *config.Config) (source, error) {
 	switch cfg.Source {
 	case config.SourceGitHub:
 		// Create GitHub client
 		ctx := context.Background()
 		hubClient := hub.NewClient(ctx, cfg.GitHubToken)
+		if cfg.GitHubURL != "" {
+			var err error
+			hubClient, err = hub.NewEnterpriseClient(ctx, cfg.GitHubURL, cfg.GitHubUploadURL, cfg.GitHubToken)
+			if err != nil {
+				return source{}, fmt.Errorf("could not create GitHub Enterprise Server client: %s: %w", cfg.GitHubURL, err)
+			}
+		}
 
 		// List repositories
-		repos, err := hub.ListRepos(ctx, hubClient, cfg.GitHubOwner, cfg.SkipPrivate, cfg.SkipForks)
+		var repos []*github.Repository
+		var err error
+		if cfg.GitHubStarred {
+			repos, err = hub.ListStarred(ctx, hubClient, cfg.GitHubOwner, cfg.SkipPrivate, cfg.SkipForks)
+		} else if cfg.GitHubSearch != "" {
+			var total int
+			repos, total, err = hub.SearchRepos(ctx, hubClient, cfg.GitHubSearch, cfg.SkipPrivate, cfg.SkipForks)
+			if err != nil {
+				return source{}, fmt.Errorf("could not search GitHub repos: %s: %w", cfg.GitHubSearch, err)
+			}
+			if total > hub.SearchLimit {
+				log.Warn("GitHub search matched more repositories than can be listed", zap.String("query", cfg.GitHubSearch), zap.Int("total", total), zap.Int("limit", hub.SearchLimit))
+			}
+		} else {
+			repos, err = hub.ListRepos(ctx, hubClient, cfg.GitHubOwner, cfg.GitHubAffiliation, cfg.SkipPrivate, cfg.SkipForks)
+		}
 		if err != nil {
-			return nil, gitea.MigrateRepoOption{}, fmt.Errorf("could not get GitHub repos: %s: %w", cfg.GitHubOwner, err)
+			return source{}, fmt.Errorf("could not get GitHub repos: %s: %w", strings.Join(cfg.GitHubOwner, " "), err)
 		}
 
-		return hub.ConvertList(repos), gitea.MigrateRepoOption{
-			Service:   gitea.GitServiceGithub,
-			AuthToken: cfg.GitHubToken,
+		convRepos := hub.ConvertList(repos)
+
+		// List gists
+		if cfg.GitHubGists {
+			gists, err := hub.ListGists(ctx, hubClient, cfg.GitHubOwner, cfg.SkipPrivate)
+			if err != nil {
+				return source{}, fmt.Errorf("could not get GitHub gists: %s: %w", strings.Join(cfg.GitHubOwner, " "), err)
+			}
+
+			gistRepos, err := hub.ConvertGistList(gists, cfg.GitHubGistName)
+			if err != nil {
+				return source{}, fmt.Errorf("could not execute gist name template: %w", err)
+			}
+			convRepos = append(convRepos, gistRepos...)
+		}
+
+		return source{
+			repos: convRepos,
+			migrateRepoOption: gitea.MigrateRepoOption{
+				Service:   gitea.GitServiceGithub,
+				AuthToken: cfg.GitHubToken,
+			},
+			getOwner: func(name string) (tea.SourceOwner, error) {
+				return hub.GetOwner(ctx, hubClient, name)
+			},
+			getRefs: func(repo *tea.SourceRepository) (tea.Refs, error) {
+				if repo.Plain {
+					return remote.ListRefs(repo.URLS[0], hub.GitUsername, cfg.GitHubToken)
+				}
+
+				return hub.ListRefs(ctx, hubClient, repo.Owner, repo.Name)
+			},
 		}, nil
 	case config.SourceGitea:
-		// Create Gitea client
-		srcClient, err := gitea.NewClient(cfg.GiteaURL, gitea.SetToken(cfg.GiteaToken))
+		return getGiteaSource(cfg, tea.FlavorGitea, cfg.GiteaURL, cfg.GiteaToken, cfg.GiteaOwner)
+	case config.SourceForgejo:
+		return getGiteaSource(cfg, tea.FlavorForgejo, cfg.ForgejoURL, cfg.ForgejoToken, cfg.ForgejoOwner)
+	case config.SourceBitbucket:
+		// Create Bitbucket client
+		bucketClient := bucket.NewClient(cfg.BitbucketURL, cfg.BitbucketUsername, cfg.BitbucketToken)
+
+		// List repositories
+		repos, err := bucket.ListRepos(bucketClient, cfg.BitbucketOwner, cfg.SkipPrivate, cfg.SkipForks)
 		if err != nil {
-			return nil, gitea.MigrateRepoOption{}, fmt.Errorf("could not create source Gitea client: %s: %w", cfg.GiteaURL, err)
+			return source{}, fmt.Errorf("could not get Bitbucket repos: %s: %w", cfg.BitbucketOwner, err)
 		}
 
+		username, password := bucketClient.GitAuth()
+		return source{
+			repos: repos,
+			migrateRepoOption: gitea.MigrateRepoOption{
+				Service:      gitea.GitServicePlain,
+				AuthUsername: username,
+				AuthPassword: password,
+			},
+			getOwner: func(name string) (tea.SourceOwner, error) {
+				return bucket.GetOwner(bucketClient, name)
+			},
+			getRefs: func(repo *tea.SourceRepository) (tea.Refs, error) {
+				return bucket.ListRefs(bucketClient, repo.Owner, repo.Name)
+			},
+		}, nil
+	case config.SourceGogs:
+		// Create Gogs client
+		gogsClient := gogs.NewClient(cfg.GogsURL, cfg.GogsToken)
+
 		// List repositories
-		repos, err := tea.ListRepos(srcClient, cfg.GiteaOwner, cfg.SkipPrivate, cfg.SkipForks)
+		repos, err := gogs.ListRepos(gogsClient, cfg.GogsOwner, cfg.SkipPrivate, cfg.SkipForks)
 		if err != nil {
-			return nil, gitea.MigrateRepoOption{}, fmt.Errorf("could not set source Gitea repos: %s: %w", cfg.GiteaOwner, err)
+			return source{}, fmt.Errorf("could not get Gogs repos: %s: %w", cfg.GogsOwner, err)
 		}
 
-		var getTopics func(r *gitea.Repository) ([]string, error)
-		if cfg.SyncTopics {
-			getTopics = func(r *gitea.Repository) ([]string, error) {
-				topics, _, err := srcClient.ListRepoTopics(r.Owner.UserName, r.Name, gitea.ListRepoTopicsOptions{})
-				if err != nil {
-					return nil, fmt.Errorf("could not list topics: %s: %w", r.FullName, err)
-				}
+		return source{
+			repos: gogs.ConvertList(repos),
+			migrateRepoOption: gitea.MigrateRepoOption{
+				Service:   gitea.GitServiceGogs,
+				AuthToken: cfg.GogsToken,
+			},
+			getOwner: func(name string) (tea.SourceOwner, error) {
+				return gogs.GetOwner(gogsClient, name)
+			},
+			getRefs: func(repo *tea.SourceRepository) (tea.Refs, error) {
+				return gogs.ListRefs(gogsClient, repo.Owner, repo.Name)
+			},
+		}, nil
+	case config.SourceOneDev:
+		// Create OneDev client
+		onedevClient := onedev.NewClient(cfg.OneDevURL, cfg.OneDevUsername, cfg.OneDevToken)
+
+		// List repositories
+		repos, err := onedev.ListRepos(onedevClient, cfg.OneDevOwner, cfg.SkipForks)
+		if err != nil {
+			return source{}, fmt.Errorf("could not get OneDev projects: %s: %w", cfg.OneDevOwner, err)
+		}
+
+		return source{
+			repos: repos,
+			migrateRepoOption: gitea.MigrateRepoOption{
+				Service:      tea.GitServiceOneDev,
+				AuthUsername: cfg.OneDevUsername,
+				AuthPassword: cfg.OneDevToken,
+			},
+		}, nil
+	case config.SourceGitBucket:
+		// Create GitBucket client
+		ctx := context.Background()
+		hubClient, err := hub.NewEnterpriseClient(ctx, cfg.GitBucketURL, "", cfg.GitBucketToken)
+		if err != nil {
+			return source{}, fmt.Errorf("could not create GitBucket client: %s: %w", cfg.GitBucketURL, err)
+		}
 
-				return topics, nil
+		// List repositories
+		repos, err := hub.ListRepos(ctx, hubClient, strings.Fields(cfg.GitBucketOwner), "", cfg.SkipPrivate, cfg.SkipForks)
+		if err != nil {
+			return source{}, fmt.Errorf("could not get GitBucket repos: %s: %w", cfg.GitBucketOwner, err)
+		}
+
+		return source{
+			repos: hub.ConvertList(repos),
+			migrateRepoOption: gitea.MigrateRepoOption{
+				Service:   tea.GitServiceGitBucket,
+				AuthToken: cfg.GitBucketToken,
+			},
+			getOwner: func(name string) (tea.SourceOwner, error) {
+				return hub.GetOwner(ctx, hubClient, name)
+			},
+			getRefs: func(repo *tea.SourceRepository) (tea.Refs, error) {
+				return hub.ListRefs(ctx, hubClient, repo.Owner, repo.Name)
+			},
+		}, nil
+	case config.SourceURLs:
+		// Read urls
+		entries, err := cfg.GetURLEntries()
+		if err != nil {
+			return source{}, err
+		}
+
+		// List refs once per run, they tell if the repository is empty and are reused to verify it
+		refs := make(map[string]tea.Refs)
+		repos := make([]tea.SourceRepository, len(entries))
+		for i, entry := range entries {
+			repos[i] = remote.Convert(entry.Owner, entry.Name, entry.URL)
+			if r, err := remote.ListRefs(entry.URL, cfg.URLsUsername, cfg.URLsPassword); err != nil {
+				log.Warn("could not list refs", zap.String("url", entry.URL), zap.Error(err))
+			} else {
+				refs[entry.URL] = r
+				repos[i].Empty = gitea.OptionalBool(len(r) == 0)
 			}
-		} else {
-			getTopics = func(r *gitea.Repository) ([]string, error) {
-				return []string{}, nil
+		}
+
+		return source{
+			repos: repos,
+			migrateRepoOption: gitea.MigrateRepoOption{
+				Service:      gitea.GitServicePlain,
+				AuthUsername: cfg.URLsUsername,
+				AuthPassword: cfg.URLsPassword,
+			},
+			getRefs: func(repo *tea.SourceRepository) (tea.Refs, error) {
+				if r, ok := refs[repo.URLS[0]]; ok {
+					return r, nil
+				}
+
+				return remote.ListRefs(repo.URLS[0], cfg.URLsUsername, cfg.URLsPassword)
+			},
+			verify: true,
+		}, nil
+	case config.SourceSourceHut:
+		// Create SourceHut client
+		srhtClient := srht.NewClient(cfg.SourceHutURL, cfg.SourceHutToken)
+
+		// List repositories
+		owner, srhtRepos, err := srht.ListRepos(srhtClient, cfg.SourceHutOwner)
+		if err != nil {
+			return source{}, fmt.Errorf("could not get SourceHut repos: %s: %w", cfg.SourceHutOwner, err)
+		}
+
+		var repos []tea.SourceRepository
+		for _, repo := range srht.ConvertList(srhtClient, owner, srhtRepos) {
+			if cfg.SkipPrivate && repo.Private {
+				continue
 			}
+			repos = append(repos, repo)
 		}
 
-		convRepos, err := tea.ConvertList(repos, getTopics)
+		return source{
+			repos: repos,
+			migrateRepoOption: gitea.MigrateRepoOption{
+				Service:      gitea.GitServicePlain,
+				AuthUsername: owner,
+				AuthPassword: cfg.SourceHutToken,
+			},
+			getOwner: func(name string) (tea.SourceOwner, error) {
+				return srht.GetOwner(srhtClient, name)
+			},
+			getRefs: func(repo *tea.SourceRepository) (tea.Refs, error) {
+				return remote.ListRefs(repo.URLS[0], owner, cfg.SourceHutToken)
+			},
+		}, nil
+	case config.SourceAzure:
+		// Create Azure DevOps client
+		azureClient := azure.NewClient(cfg.AzureURL, cfg.AzureOrg, cfg.AzureToken)
+
+		// List repositories
+		repos, err := azure.ListRepos(azureClient, cfg.AzureProject, cfg.SkipPrivate, cfg.SkipForks)
 		if err != nil {
-			return nil, gitea.MigrateRepoOption{}, err
+			return source{}, fmt.Errorf("could not get Azure DevOps repos: %s: %w", cfg.AzureOrg, err)
 		}
 
-		return convRepos, gitea.MigrateRepoOption{
-			Service:   gitea.GitServiceGitea,
-			AuthToken: cfg.GiteaToken,
+		return source{
+			repos: azure.ConvertList(repos),
+			migrateRepoOption: gitea.MigrateRepoOption{
+				Service:      gitea.GitServicePlain,
+				AuthUsername: azure.GitUsername,
+				AuthPassword: cfg.AzureToken,
+			},
+			getOwner: func(name string) (tea.SourceOwner, error) {
+				return azure.GetOwner(azureClient, name)
+			},
+			getRefs: func(repo *tea.SourceRepository) (tea.Refs, error) {
+				return remote.ListRefs(repo.URLS[0], azure.GitUsername, cfg.AzureToken)
+			},
 		}, nil
 	default:
 		panic(fmt.Sprintf("invalid SOURCE: %s", cfg.Source))
 	}
 }
+
+func getGiteaSource(cfg *config.Config, flavor tea.Flavor, url, token, owner string) (source, error) {
+	// Create Gitea client
+	srcClient, err := gitea.NewClient(url, gitea.SetToken(token))
+	if err != nil {
+		return source{}, fmt.Errorf("could not create source %s client: %s: %w", flavor, url, err)
+	}
+
+	// Detect server
+	server, err := tea.GetServer(srcClient, url)
+	if err != nil {
+		return source{}, fmt.Errorf("could not get source %s server: %s: %w", flavor, url, err)
+	}
+	if server.Flavor != flavor {
+		log.Warn("source is not the configured type", zap.String("url", url), zap.String("type", string(flavor)), zap.String("detected", string(server.Flavor)))
+	}
+
+	// List repositories
+	repos, err := tea.ListRepos(srcClient, owner, cfg.SkipPrivate, cfg.SkipForks)
+	if err != nil {
+		return source{}, fmt.Errorf("could not set source %s repos: %s: %w", flavor, owner, err)
+	}
+
+	syncTopics := false
+	for _, dest := range cfg.Destinations {
+		syncTopics = syncTopics || dest.SyncTopics
+	}
+
+	var getTopics func(r *gitea.Repository) ([]string, error)
+	if syncTopics {
+		getTopics = func(r *gitea.Repository) ([]string, error) {
+			topics, _, err := srcClient.ListRepoTopics(r.Owner.UserName, r.Name, gitea.ListRepoTopicsOptions{})
+			if err != nil {
+				return nil, fmt.Errorf("could not list topics: %s: %w", r.FullName, err)
+			}
+
+			return topics, nil
+		}
+	} else {
+		getTopics = func(r *gitea.Repository) ([]string, error) {
+			return []string{}, nil
+		}
+	}
+
+	convRepos, err := tea.ConvertList(repos, getTopics)
+	if err != nil {
+		return source{}, err
+	}
+
+	return source{
+		repos:  convRepos,
+		flavor: server.Flavor,
+		migrateRepoOption: gitea.MigrateRepoOption{
+			Service:   gitea.GitServiceGitea,
+			AuthToken: token,
+		},
+		getOwner: func(name string) (tea.SourceOwner, error) {
+			return tea.GetOwner(srcClient, name)
+		},
+		getRefs: func(repo *tea.SourceRepository) (tea.Refs, error) {
+			return tea.ListRefs(srcClient, repo.Owner, repo.Name)
+		},
+	}, nil
+}
@@ -0,0 +1,146 @@
+package onedev
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/ItsNotGoodName/sync-gitea-mirrors/tea"
+)
+
+type Project struct {
+	ID           int64  `json:"id"`
+	ParentID     *int64 `json:"parentId"`
+	ForkedFromID *int64 `json:"forkedFromId"`
+	Name         string `json:"name"`
+	Description  string `json:"description"`
+}
+
+type Client struct {
+	URL      string
+	Username string
+	Token    string
+	HTTP     *http.Client
+}
+
+func NewClient(url, username, token string) *Client {
+	return &Client{
+		URL:      strings.TrimSuffix(url, "/"),
+		Username: username,
+		Token:    token,
+		HTTP:     http.DefaultClient,
+	}
+}
+
+func (c *Client) get(path string, v any) error {
+	req, err := http.NewRequest("GET", c.URL+"/~api"+path, nil)
+	if err != nil {
+		return err
+	}
+	if c.Token != "" {
+		req.SetBasicAuth(c.Username, c.Token)
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.HTTP.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s: %s", path, resp.Status)
+	}
+
+	return json.NewDecoder(resp.Body).Decode(v)
+}
+
+func ListProjects(c *Client) ([]Project, error) {
+	var projects []Project
+	offset := 0
+	limit := 100
+	for {
+		var page []Project
+		if err := c.get(fmt.Sprintf("/projects?offset=%d&count=%d", offset, limit), &page); err != nil {
+			return nil, err
+		}
+		projects = append(projects, page...)
+		if len(page) < limit {
+			return projects, nil
+		}
+		offset += limit
+	}
+}
+
+// ListRepos lists the projects under the parent project owner, or every project the user can access when owner is empty.
+// The owner of a repository is the path of its parent project with "/" replaced by "-" (e.g. "group-sub").
+func ListRepos(c *Client, owner string, skipForks bool) ([]tea.SourceRepository, error) {
+	projects, err := ListProjects(c)
+	if err != nil {
+		return nil, err
+	}
+
+	byID := make(map[int64]Project, len(projects))
+	for _, p := range projects {
+		byID[p.ID] = p
+	}
+
+	var repos []tea.SourceRepository
+	for _, p := range projects {
+		if skipForks && p.ForkedFromID != nil {
+			continue
+		}
+
+		parent := parentPath(byID, p)
+		if owner != "" && !strings.EqualFold(parent, owner) {
+			continue
+		}
+
+		repos = append(repos, Convert(c, p, parent))
+	}
+
+	return repos, nil
+}
+
+func Convert(c *Client, p Project, parent string) tea.SourceRepository {
+	path := p.Name
+	if parent != "" {
+		path = parent + "/" + p.Name
+	}
+	htmlURL := c.URL + "/" + path
+
+	return tea.SourceRepository{
+		SyncRepository: tea.SyncRepository{
+			Description: p.Description,
+			// OneDev does not expose project visibility, access is controlled by roles
+			Private: true,
+		},
+		Owner:   Owner(parent),
+		Name:    p.Name,
+		Fork:    p.ForkedFromID != nil,
+		HTMLURL: htmlURL,
+		URLS:    []string{htmlURL, c.URL + "/projects/" + path},
+		// The OneDev migration of Gitea only accepts top-level projects
+		Plain: parent != "",
+	}
+}
+
+// Owner returns the owner of repositories under the parent project path, "/" is not allowed in owner names.
+func Owner(parent string) string {
+	return strings.ReplaceAll(parent, "/", "-")
+}
+
+func parentPath(byID map[int64]Project, p Project) string {
+	var names []string
+	for p.ParentID != nil {
+		parent, ok := byID[*p.ParentID]
+		if !ok {
+			break
+		}
+		names = append([]string{parent.Name}, names...)
+		p = parent
+	}
+
+	return strings.Join(names, "/")
+}
@@ -0,0 +1,128 @@
+package remote
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/ItsNotGoodName/sync-gitea-mirrors/tea"
+)
+
+func Convert(owner, name, url string) tea.SourceRepository {
+	htmlURL := strings.TrimSuffix(url, ".git")
+
+	return tea.SourceRepository{
+		Owner:   owner,
+		Name:    name,
+		HTMLURL: htmlURL,
+		URLS:    []string{url, htmlURL},
+	}
+}
+
+// ListRefs lists the branches and tags of a repository using the git HTTP protocol like git ls-remote.
+func ListRefs(url, username, password string) (tea.Refs, error) {
+	req, err := http.NewRequest("GET", strings.TrimSuffix(url, "/")+"/info/refs?service=git-upload-pack", nil)
+	if err != nil {
+		return nil, err
+	}
+	if username != "" || password != "" {
+		req.SetBasicAuth(username, password)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s: %s", url, resp.Status)
+	}
+	if resp.Header.Get("Content-Type") != "application/x-git-upload-pack-advertisement" {
+		// Dumb HTTP servers return the info/refs file
+		return parseInfoRefs(resp.Body)
+	}
+
+	return parseAdvertisement(resp.Body)
+}
+
+// parseInfoRefs parses the lines of an info/refs file.
+func parseInfoRefs(r io.Reader) (tea.Refs, error) {
+	refs := make(tea.Refs)
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		sha, ref, ok := strings.Cut(scanner.Text(), "\t")
+		if !ok {
+			return nil, fmt.Errorf("invalid info/refs line: %q", scanner.Text())
+		}
+		addRef(refs, sha, ref)
+	}
+
+	return refs, scanner.Err()
+}
+
+// parseAdvertisement parses the pkt-lines of a ref advertisement, tags are resolved to the commit they point to.
+func parseAdvertisement(r io.Reader) (tea.Refs, error) {
+	refs := make(tea.Refs)
+	br := bufio.NewReader(r)
+	for {
+		line, flush, err := readPktLine(br)
+		if err == io.EOF {
+			return refs, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if flush || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		// Capabilities follow the first ref
+		line, _, _ = strings.Cut(strings.TrimSuffix(line, "\n"), "\x00")
+		sha, ref, ok := strings.Cut(line, " ")
+		if !ok {
+			return nil, fmt.Errorf("invalid ref advertisement: %q", line)
+		}
+
+		addRef(refs, sha, ref)
+	}
+}
+
+// addRef adds branches and tags to refs, peeled tags replace the tag object with the commit.
+func addRef(refs tea.Refs, sha, ref string) {
+	ref, peeled := strings.CutSuffix(ref, "^{}")
+	if !strings.HasPrefix(ref, "refs/heads/") && !strings.HasPrefix(ref, "refs/tags/") {
+		return
+	}
+	if _, ok := refs[ref]; peeled || !ok {
+		refs[ref] = sha
+	}
+}
+
+func readPktLine(r *bufio.Reader) (string, bool, error) {
+	var size [4]byte
+	if _, err := io.ReadFull(r, size[:]); err != nil {
+		return "", false, err
+	}
+
+	n, err := strconv.ParseUint(string(size[:]), 16, 16)
+	if err != nil {
+		return "", false, fmt.Errorf("invalid pkt-line length: %q", size)
+	}
+	if n == 0 {
+		return "", true, nil
+	}
+	if n < 4 {
+		return "", false, fmt.Errorf("invalid pkt-line length: %d", n)
+	}
+
+	data := make([]byte, n-4)
+	if _, err := io.ReadFull(r, data); err != nil {
+		return "", false, err
+	}
+
+	return string(data), false, nil
+}
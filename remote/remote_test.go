@@ -0,0 +1,134 @@
+package remote
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ItsNotGoodName/sync-gitea-mirrors/tea"
+)
+
+const (
+	commit1   = "ee11437c9580f365064e93ca7b8aa91d67e7caf4"
+	commit2   = "1f6b2f0c1f1d2e3a4b5c6d7e8f90a1b2c3d4e5f6"
+	tagObject = "175cb7c90067e81c4f710d78143e0b53204875f4"
+)
+
+var wantRefs = tea.Refs{
+	"refs/heads/master": commit1,
+	"refs/heads/dev":    commit2,
+	"refs/tags/v1":      commit1,
+	"refs/tags/v2":      commit2,
+}
+
+func pktLine(s string) string {
+	return fmt.Sprintf("%04x%s", len(s)+4, s)
+}
+
+func smartResponse() string {
+	return pktLine("# service=git-upload-pack\n") + "0000" +
+		pktLine(commit1+" HEAD\x00multi_ack symref=HEAD:refs/heads/master agent=git/2.39.5\n") +
+		pktLine(commit2+" refs/heads/dev\n") +
+		pktLine(commit1+" refs/heads/master\n") +
+		pktLine(commit2+" refs/pull/1/head\n") +
+		pktLine(tagObject+" refs/tags/v1\n") +
+		pktLine(commit1+" refs/tags/v1^{}\n") +
+		pktLine(commit2+" refs/tags/v2\n") +
+		"0000"
+}
+
+func dumbResponse() string {
+	return commit2 + "\trefs/heads/dev\n" +
+		commit1 + "\trefs/heads/master\n" +
+		tagObject + "\trefs/tags/v1\n" +
+		commit1 + "\trefs/tags/v1^{}\n" +
+		commit2 + "\trefs/tags/v2\n"
+}
+
+func TestParseAdvertisement(t *testing.T) {
+	refs, err := parseAdvertisement(strings.NewReader(smartResponse()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(refs, wantRefs) {
+		t.Errorf("parseAdvertisement() = %v, want %v", refs, wantRefs)
+	}
+}
+
+func TestParseAdvertisementEmpty(t *testing.T) {
+	// Empty repositories advertise capabilities without refs
+	body := pktLine("# service=git-upload-pack\n") + "0000" +
+		pktLine("0000000000000000000000000000000000000000 capabilities^{}\x00multi_ack\n") + "0000"
+	refs, err := parseAdvertisement(strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(refs) != 0 {
+		t.Errorf("parseAdvertisement() = %v, want no refs", refs)
+	}
+}
+
+func TestParseAdvertisementInvalid(t *testing.T) {
+	for _, body := range []string{"zzzz", "0003", pktLine("no-space\n"), "0010short"} {
+		if _, err := parseAdvertisement(strings.NewReader(body)); err == nil {
+			t.Errorf("parseAdvertisement(%q) returned no error", body)
+		}
+	}
+}
+
+func TestParseInfoRefs(t *testing.T) {
+	refs, err := parseInfoRefs(strings.NewReader(dumbResponse()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(refs, wantRefs) {
+		t.Errorf("parseInfoRefs() = %v, want %v", refs, wantRefs)
+	}
+
+	if _, err := parseInfoRefs(strings.NewReader("<html>not a repository</html>\n")); err == nil {
+		t.Error("parseInfoRefs() of html returned no error")
+	}
+}
+
+func TestListRefs(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+	}{
+		{"smart", "application/x-git-upload-pack-advertisement", smartResponse()},
+		{"dumb", "text/plain", dumbResponse()},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != "/owner/repo.git/info/refs" || r.URL.Query().Get("service") != "git-upload-pack" {
+					http.NotFound(w, r)
+					return
+				}
+				if username, password, _ := r.BasicAuth(); username != "user" || password != "pass" {
+					w.WriteHeader(http.StatusUnauthorized)
+					return
+				}
+				w.Header().Set("Content-Type", tt.contentType)
+				fmt.Fprint(w, tt.body)
+			}))
+			defer server.Close()
+
+			refs, err := ListRefs(server.URL+"/owner/repo.git", "user", "pass")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(refs, wantRefs) {
+				t.Errorf("ListRefs() = %v, want %v", refs, wantRefs)
+			}
+
+			if _, err := ListRefs(server.URL+"/owner/repo.git", "user", "wrong"); err == nil {
+				t.Error("ListRefs() with wrong password returned no error")
+			}
+		})
+	}
+}
@@ -0,0 +1,185 @@
+package srht
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/ItsNotGoodName/sync-gitea-mirrors/tea"
+)
+
+const (
+	VisibilityPublic   = "PUBLIC"
+	VisibilityUnlisted = "UNLISTED"
+	VisibilityPrivate  = "PRIVATE"
+)
+
+type Repository struct {
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	Visibility  string    `json:"visibility"`
+	Updated     time.Time `json:"updated"`
+	HEAD        *struct {
+		Name string `json:"name"`
+	} `json:"HEAD"`
+}
+
+type User struct {
+	CanonicalName string `json:"canonicalName"`
+	Username      string `json:"username"`
+	URL           string `json:"url"`
+	Bio           string `json:"bio"`
+}
+
+type Client struct {
+	URL   string
+	Token string
+	HTTP  *http.Client
+}
+
+func NewClient(url, token string) *Client {
+	return &Client{
+		URL:   strings.TrimSuffix(url, "/"),
+		Token: token,
+		HTTP:  http.DefaultClient,
+	}
+}
+
+func (c *Client) query(query string, variables map[string]any, v any) error {
+	body, err := json.Marshal(map[string]any{"query": query, "variables": variables})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest("POST", c.URL+"/query", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+c.Token)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.HTTP.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	var res struct {
+		Data   json.RawMessage `json:"data"`
+		Errors []struct {
+			Message string `json:"message"`
+		} `json:"errors"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&res); err != nil {
+		return fmt.Errorf("%s: %w", resp.Status, err)
+	}
+	if len(res.Errors) > 0 {
+		return fmt.Errorf("%s", res.Errors[0].Message)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s", resp.Status)
+	}
+
+	return json.Unmarshal(res.Data, v)
+}
+
+// username removes the "~" of canonical names.
+func username(owner string) string {
+	return strings.TrimPrefix(owner, "~")
+}
+
+const repositoriesFields = `username repositories(cursor: $cursor) { results { name description visibility updated HEAD { name } } cursor }`
+
+// ListRepos lists the repositories of a user or the authenticated user when owner is empty.
+// The owner of the returned repositories is the username without "~" (e.g. "sircmpwn").
+func ListRepos(c *Client, owner string) (string, []Repository, error) {
+	query := `query ($cursor: Cursor) { user: me { ` + repositoriesFields + ` } }`
+	variables := map[string]any{}
+	if owner != "" {
+		query = `query ($cursor: Cursor, $username: String!) { user(username: $username) { ` + repositoriesFields + ` } }`
+		variables["username"] = username(owner)
+	}
+
+	var repos []Repository
+	var cursor *string
+	for {
+		variables["cursor"] = cursor
+
+		var data struct {
+			User *struct {
+				Username     string `json:"username"`
+				Repositories struct {
+					Results []Repository `json:"results"`
+					Cursor  *string      `json:"cursor"`
+				} `json:"repositories"`
+			} `json:"user"`
+		}
+		if err := c.query(query, variables, &data); err != nil {
+			return "", nil, err
+		}
+		if data.User == nil {
+			return "", nil, fmt.Errorf("user not found: %s", owner)
+		}
+
+		repos = append(repos, data.User.Repositories.Results...)
+		if data.User.Repositories.Cursor == nil {
+			return data.User.Username, repos, nil
+		}
+		cursor = data.User.Repositories.Cursor
+	}
+}
+
+func ConvertList(c *Client, owner string, srhtRepos []Repository) []tea.SourceRepository {
+	repos := make([]tea.SourceRepository, len(srhtRepos))
+	for i := range repos {
+		repos[i] = Convert(c, owner, srhtRepos[i])
+	}
+	return repos
+}
+
+func Convert(c *Client, owner string, r Repository) tea.SourceRepository {
+	var defaultBranch string
+	if r.HEAD != nil {
+		defaultBranch = strings.TrimPrefix(r.HEAD.Name, "refs/heads/")
+	}
+	htmlURL := CloneURL(c, owner, r.Name)
+
+	return tea.SourceRepository{
+		SyncRepository: tea.SyncRepository{
+			Description:   r.Description,
+			DefaultBranch: defaultBranch,
+			Private:       r.Visibility != VisibilityPublic,
+			PushedAt:      r.Updated,
+		},
+		Owner:   owner,
+		Name:    r.Name,
+		HTMLURL: htmlURL,
+		URLS:    []string{htmlURL},
+	}
+}
+
+// CloneURL returns the HTTPS clone URL which is also the URL of the repository's page.
+func CloneURL(c *Client, owner, name string) string {
+	return c.URL + "/~" + username(owner) + "/" + name
+}
+
+func GetOwner(c *Client, owner string) (tea.SourceOwner, error) {
+	var data struct {
+		User *User `json:"user"`
+	}
+	if err := c.query(`query ($username: String!) { user(username: $username) { canonicalName username url bio } }`, map[string]any{"username": username(owner)}, &data); err != nil {
+		return tea.SourceOwner{}, err
+	}
+	if data.User == nil {
+		return tea.SourceOwner{}, fmt.Errorf("user not found: %s", owner)
+	}
+
+	return tea.SourceOwner{
+		Name:        data.User.Username,
+		Description: data.User.Bio,
+		Website:     data.User.URL,
+	}, nil
+}
@@ -0,0 +1,95 @@
+package tea
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+
+	"code.gitea.io/sdk/gitea"
+)
+
+const MaxAvatarSize = 5 << 20
+
+// HashAvatar returns the file name Gitea gives to an uploaded avatar.
+func HashAvatar(id int64, data []byte) string {
+	h := sha256.New()
+	h.Write([]byte(fmt.Sprintf("%d-", id)))
+	h.Write(data)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func DiffAvatar(teaRepo *gitea.Repository, data []byte) bool {
+	u, err := url.Parse(teaRepo.AvatarURL)
+	if err != nil || teaRepo.AvatarURL == "" {
+		return true
+	}
+
+	return path.Base(u.Path) != HashAvatar(teaRepo.ID, data)
+}
+
+func DownloadAvatar(avatarURL string) ([]byte, error) {
+	resp, err := http.Get(avatarURL)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("could not download avatar: %s: %s", avatarURL, resp.Status)
+	}
+
+	return io.ReadAll(io.LimitReader(resp.Body, MaxAvatarSize))
+}
+
+// SyncAvatar uploads the avatar at avatarURL to teaRepo when it changed.
+// The SDK does not support avatars so the API is called directly.
+func SyncAvatar(destURL, destToken string, teaRepo *gitea.Repository, avatarURL string) (bool, error) {
+	data, err := DownloadAvatar(avatarURL)
+	if err != nil {
+		return false, err
+	}
+
+	if !DiffAvatar(teaRepo, data) {
+		return false, nil
+	}
+
+	if err := updateAvatar(destURL, destToken, fmt.Sprintf("/repos/%s/%s/avatar", url.PathEscape(teaRepo.Owner.UserName), url.PathEscape(teaRepo.Name)), data); err != nil {
+		return false, fmt.Errorf("could not update repo avatar: %w", err)
+	}
+
+	return true, nil
+}
+
+func updateAvatar(destURL, destToken, endpoint string, data []byte) error {
+	body, err := json.Marshal(map[string]string{"image": base64.StdEncoding.EncodeToString(data)})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest("POST", strings.TrimSuffix(destURL, "/")+"/api/v1"+endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "token "+destToken)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("%s", resp.Status)
+	}
+
+	return nil
+}
@@ -0,0 +1,97 @@
+package tea
+
+import (
+	"errors"
+	"fmt"
+	"time"
+	"unicode/utf8"
+
+	"code.gitea.io/sdk/gitea"
+)
+
+const MigrationPollInterval = 5 * time.Second
+
+// MaxMigrateDescription is the longest description in bytes the SDK accepts when migrating.
+const MaxMigrateDescription = 255
+
+var ErrMigrationRunning = errors.New("migration is still running")
+
+// MigrationDone returns false when teaRepo is a mirror that has not finished its initial clone.
+func MigrationDone(teaRepo *gitea.Repository) bool {
+	return !teaRepo.Mirror || !teaRepo.Empty || !teaRepo.MirrorUpdated.IsZero()
+}
+
+// Migrate migrates a repository and waits for it to finish.
+// Failed migrations are deleted and retried, ErrMigrationRunning is returned when the migration did not finish in time.
+func Migrate(client *gitea.Client, opts gitea.MigrateRepoOption, wait time.Duration, retries int) (*gitea.Repository, error) {
+	var reterr error
+	for attempt := 0; attempt <= retries; attempt++ {
+		teaRepo, err := migrate(client, opts, wait)
+		if err == nil || errors.Is(err, ErrMigrationRunning) {
+			return teaRepo, err
+		}
+		reterr = errors.Join(reterr, err)
+
+		if err := DeleteFailedMigration(client, opts.RepoOwner, opts.RepoName); err != nil {
+			return nil, errors.Join(reterr, err)
+		}
+	}
+
+	return nil, reterr
+}
+
+func migrate(client *gitea.Client, opts gitea.MigrateRepoOption, wait time.Duration) (*gitea.Repository, error) {
+	teaRepo, _, err := client.MigrateRepo(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	deadline := time.Now().Add(wait)
+	for !MigrationDone(teaRepo) {
+		if time.Now().After(deadline) {
+			return teaRepo, ErrMigrationRunning
+		}
+		time.Sleep(MigrationPollInterval)
+
+		if teaRepo, err = GetRepoOrNil(client, opts.RepoOwner, opts.RepoName); err != nil {
+			return nil, err
+		}
+		if teaRepo == nil {
+			return nil, fmt.Errorf("migration failed")
+		}
+	}
+
+	return teaRepo, nil
+}
+
+// MigrateDescription truncates description to MaxMigrateDescription, the full description is set by Sync.
+func MigrateDescription(description string) string {
+	if len(description) <= MaxMigrateDescription {
+		return description
+	}
+
+	n := MaxMigrateDescription
+	for n > 0 && !utf8.RuneStart(description[n]) {
+		n--
+	}
+
+	return description[:n]
+}
+
+// DeleteFailedMigration deletes the repository when it has not finished its migration.
+func DeleteFailedMigration(client *gitea.Client, owner, repoName string) error {
+	teaRepo, err := GetRepoOrNil(client, owner, repoName)
+	if err != nil {
+		return err
+	}
+
+	if teaRepo == nil || MigrationDone(teaRepo) {
+		return nil
+	}
+
+	if _, err := client.DeleteRepo(owner, repoName); err != nil {
+		return fmt.Errorf("could not delete failed migration: %w", err)
+	}
+
+	return nil
+}
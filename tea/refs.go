@@ -0,0 +1,87 @@
+package tea
+
+import (
+	"sort"
+
+	"code.gitea.io/sdk/gitea"
+)
+
+// Refs maps full ref names (e.g. "refs/heads/main") to commit SHAs.
+type Refs map[string]string
+
+type RefsDrift struct {
+	Missing   []string
+	Different []string
+}
+
+func (d RefsDrift) Drifted() bool {
+	return len(d.Missing) > 0 || len(d.Different) > 0
+}
+
+// DiffRefs returns the refs of source that are missing or point to a different commit in dest.
+func DiffRefs(source, dest Refs) RefsDrift {
+	var drift RefsDrift
+	for ref, sha := range source {
+		destSHA, ok := dest[ref]
+		if !ok {
+			drift.Missing = append(drift.Missing, ref)
+		} else if destSHA != sha {
+			drift.Different = append(drift.Different, ref)
+		}
+	}
+
+	sort.Strings(drift.Missing)
+	sort.Strings(drift.Different)
+
+	return drift
+}
+
+// RefsPageSize is the page size used to list refs, it is the default MAX_RESPONSE_ITEMS of Gitea.
+const RefsPageSize = 50
+
+func ListRefs(client *gitea.Client, owner, repoName string) (Refs, error) {
+	branches, err := listPages(func(opts gitea.ListOptions) ([]*gitea.Branch, error) {
+		branches, _, err := client.ListRepoBranches(owner, repoName, gitea.ListRepoBranchesOptions{ListOptions: opts})
+		return branches, err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	tags, err := listPages(func(opts gitea.ListOptions) ([]*gitea.Tag, error) {
+		tags, _, err := client.ListRepoTags(owner, repoName, gitea.ListRepoTagsOptions{ListOptions: opts})
+		return tags, err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	refs := make(Refs, len(branches)+len(tags))
+	for _, b := range branches {
+		if b.Commit != nil {
+			refs["refs/heads/"+b.Name] = b.Commit.ID
+		}
+	}
+	for _, t := range tags {
+		if t.Commit != nil {
+			refs["refs/tags/"+t.Name] = t.Commit.SHA
+		}
+	}
+
+	return refs, nil
+}
+
+// listPages calls list for every page until a page is shorter than RefsPageSize.
+func listPages[T any](list func(opts gitea.ListOptions) ([]T, error)) ([]T, error) {
+	var values []T
+	for page := 1; ; page++ {
+		pageValues, err := list(gitea.ListOptions{Page: page, PageSize: RefsPageSize})
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, pageValues...)
+		if len(pageValues) < RefsPageSize {
+			return values, nil
+		}
+	}
+}
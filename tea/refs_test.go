@@ -0,0 +1,66 @@
+package tea
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"code.gitea.io/sdk/gitea"
+)
+
+func TestListRefsPages(t *testing.T) {
+	const branchCount, tagCount = 2*RefsPageSize + 3, RefsPageSize
+
+	page := func(w http.ResponseWriter, r *http.Request, total int, item func(i int) any) {
+		p, _ := strconv.Atoi(r.URL.Query().Get("page"))
+		limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+		if p < 1 || limit != RefsPageSize {
+			t.Errorf("%s: page=%d limit=%d", r.URL.Path, p, limit)
+		}
+
+		items := []any{}
+		for i := (p - 1) * limit; i < p*limit && i < total; i++ {
+			items = append(items, item(i))
+		}
+		json.NewEncoder(w).Encode(items)
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v1/version", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"version":"1.19.0"}`)
+	})
+	mux.HandleFunc("/api/v1/repos/owner/repo/branches", func(w http.ResponseWriter, r *http.Request) {
+		page(w, r, branchCount, func(i int) any {
+			return gitea.Branch{Name: fmt.Sprint("b", i), Commit: &gitea.PayloadCommit{ID: fmt.Sprint("sha", i)}}
+		})
+	})
+	mux.HandleFunc("/api/v1/repos/owner/repo/tags", func(w http.ResponseWriter, r *http.Request) {
+		page(w, r, tagCount, func(i int) any {
+			return gitea.Tag{Name: fmt.Sprint("t", i), Commit: &gitea.CommitMeta{SHA: fmt.Sprint("sha", i)}}
+		})
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	client, err := gitea.NewClient(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	refs, err := ListRefs(client, "owner", "repo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(refs) != branchCount+tagCount {
+		t.Errorf("ListRefs() returned %d refs, want %d", len(refs), branchCount+tagCount)
+	}
+	if sha := refs[fmt.Sprint("refs/heads/b", branchCount-1)]; sha != fmt.Sprint("sha", branchCount-1) {
+		t.Errorf("last branch = %q", sha)
+	}
+	if sha := refs[fmt.Sprint("refs/tags/t", tagCount-1)]; sha != fmt.Sprint("sha", tagCount-1) {
+		t.Errorf("last tag = %q", sha)
+	}
+}
@@ -0,0 +1,156 @@
+package tea
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"code.gitea.io/sdk/gitea"
+	"github.com/hashicorp/go-version"
+)
+
+type Flavor string
+
+const (
+	FlavorGitea   Flavor = "gitea"
+	FlavorForgejo Flavor = "forgejo"
+)
+
+// GitServiceForgejo is only understood by Forgejo instances.
+const GitServiceForgejo gitea.GitServiceType = "forgejo"
+
+// Services that are missing from the SDK.
+const (
+	GitServiceOneDev    gitea.GitServiceType = "onedev"
+	GitServiceGitBucket gitea.GitServiceType = "gitbucket"
+)
+
+// serviceVersions are the Gitea versions that added the migration service.
+var serviceVersions = map[gitea.GitServiceType]string{
+	gitea.GitServiceGogs: "1.14",
+	GitServiceOneDev:     "1.15",
+	GitServiceGitBucket:  "1.16",
+}
+
+type Server struct {
+	Flavor  Flavor
+	Version string
+	// GiteaVersion is the version of Gitea the server is compatible with, it is nil when the version could not be parsed.
+	GiteaVersion *version.Version
+	// Settings is nil when the server does not expose its settings.
+	Settings *gitea.GlobalRepoSettings
+}
+
+// Capabilities are the features of a server that are used when syncing.
+type Capabilities struct {
+	Mirrors         bool
+	Migrations      bool
+	MirrorInterval  bool
+	Topics          bool
+	LFS             bool
+	AsyncMigrations bool
+	Avatars         bool
+}
+
+func GetServer(client *gitea.Client, serverURL string) (Server, error) {
+	v, _, err := client.ServerVersion()
+	if err != nil {
+		return Server{}, fmt.Errorf("could not get server version: %w", err)
+	}
+
+	server := Server{Flavor: FlavorGitea, Version: v}
+
+	forgejoVersion, err := getForgejoVersion(serverURL)
+	if err != nil {
+		return Server{}, fmt.Errorf("could not get forgejo version: %w", err)
+	}
+	if forgejoVersion != "" {
+		server.Flavor = FlavorForgejo
+		server.Version = forgejoVersion
+	}
+
+	// Forgejo reports the Gitea version as build metadata (e.g. "7.0.0+gitea-1.22.0")
+	giteaVersion := v
+	if _, after, ok := strings.Cut(v, "+gitea-"); ok {
+		giteaVersion = after
+	}
+	server.GiteaVersion, _ = version.NewVersion(giteaVersion)
+
+	// The SDK refuses to get settings from servers before 1.13 or with a version it can not parse
+	if server.GiteaVersion != nil && server.AtLeast("1.13.0") {
+		settings, resp, err := client.GetGlobalRepoSettings()
+		if err != nil {
+			if resp == nil || resp.StatusCode != 404 {
+				return Server{}, fmt.Errorf("could not get repo settings: %w", err)
+			}
+		} else {
+			server.Settings = settings
+		}
+	}
+
+	return server, nil
+}
+
+// AtLeast returns true when the server is compatible with the given Gitea version or its version is unknown.
+func (s Server) AtLeast(v string) bool {
+	if s.GiteaVersion == nil {
+		return true
+	}
+
+	return s.GiteaVersion.Core().GreaterThanOrEqual(version.Must(version.NewVersion(v)))
+}
+
+func (s Server) Capabilities() Capabilities {
+	caps := Capabilities{
+		Mirrors:         true,
+		Migrations:      true,
+		MirrorInterval:  s.AtLeast("1.13.0"),
+		Topics:          s.AtLeast("1.9.0"),
+		LFS:             s.AtLeast("1.15.0"),
+		AsyncMigrations: s.AtLeast("1.13.0"),
+		Avatars:         s.AtLeast("1.22.0"),
+	}
+
+	if s.Settings != nil {
+		caps.Mirrors = !s.Settings.MirrorsDisabled
+		caps.Migrations = !s.Settings.MigrationsDisabled
+		caps.LFS = caps.LFS && !s.Settings.LFSDisabled
+	}
+
+	return caps
+}
+
+// MigrationService returns the service the server should use to migrate from a source of the given flavor.
+func (s Server) MigrationService(service gitea.GitServiceType, source Flavor) gitea.GitServiceType {
+	if service == gitea.GitServiceGitea && source == FlavorForgejo && s.Flavor == FlavorForgejo {
+		return GitServiceForgejo
+	}
+	if v, ok := serviceVersions[service]; ok && !s.AtLeast(v) {
+		return gitea.GitServicePlain
+	}
+
+	return service
+}
+
+func getForgejoVersion(serverURL string) (string, error) {
+	resp, err := http.Get(strings.TrimSuffix(serverURL, "/") + "/api/forgejo/v1/version")
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		// Gitea does not have the Forgejo API
+		return "", nil
+	}
+
+	var body struct {
+		Version string `json:"version"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return "", err
+	}
+
+	return body.Version, nil
+}
@@ -2,19 +2,37 @@ package tea
 
 import (
 	"strings"
+	"text/template"
 
 	"code.gitea.io/sdk/gitea"
 )
 
 type SourceRepository struct {
 	SyncRepository
-	Owner string
-	Name  string
-	Fork  bool
-	URLS  []string
+	Owner     string
+	Name      string
+	Fork      bool
+	HTMLURL   string
+	AvatarURL string
+	URLS      []string
+	// Plain is migrated as a plain git repository instead of with the service of its source.
+	Plain bool
+}
+
+type SourceOwner struct {
+	Name        string
+	FullName    string
+	Description string
+	Website     string
+	AvatarURL   string
+	Visibility  gitea.VisibleType
 }
 
 func (sr SourceRepository) GetFullName() string {
+	if sr.Owner == "" {
+		return sr.Name
+	}
+
 	return sr.Owner + "/" + sr.Name
 }
 
@@ -34,16 +52,56 @@ func (sr SourceRepository) Is(name string) bool {
 // 	return after == repoName
 // }
 
+// IsMyMirror returns true when the normalized original URL of the mirror teaRepo is one of the URLs of the source.
+// Gitea does not store the ID of the source repository so mirrors of renamed or transferred repositories do not match.
 func (sr SourceRepository) IsMyMirror(teaRepo *gitea.Repository) bool {
 	if !teaRepo.Mirror {
 		return false
 	}
 
+	originalURL := NormalizeURL(teaRepo.OriginalURL)
 	for _, url := range sr.URLS {
-		if strings.ToLower(teaRepo.OriginalURL) == strings.ToLower(url) {
+		if originalURL == NormalizeURL(url) {
 			return true
 		}
 	}
 
 	return false
 }
+
+// CanAdopt returns true when teaRepo is a mirror of a repository with the same path on one of the hosts (e.g. the old hostname of the source).
+func (sr SourceRepository) CanAdopt(teaRepo *gitea.Repository, hosts []string) bool {
+	if !teaRepo.Mirror {
+		return false
+	}
+
+	originalHost := URLHost(teaRepo.OriginalURL)
+	adoptable := false
+	for _, host := range hosts {
+		if strings.EqualFold(originalHost, host) {
+			adoptable = true
+			break
+		}
+	}
+	if !adoptable {
+		return false
+	}
+
+	originalPath := URLPath(teaRepo.OriginalURL)
+	for _, url := range sr.URLS {
+		if originalPath == URLPath(url) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (sr SourceRepository) Execute(tmpl *template.Template) (string, error) {
+	var b strings.Builder
+	if err := tmpl.Execute(&b, sr); err != nil {
+		return "", err
+	}
+
+	return b.String(), nil
+}
@@ -0,0 +1,42 @@
+package tea
+
+import (
+	"testing"
+
+	"code.gitea.io/sdk/gitea"
+)
+
+func TestCanAdopt(t *testing.T) {
+	repo := SourceRepository{URLS: []string{"https://github.com/foo/bar.git", "https://github.com/foo/bar"}}
+	tests := []struct {
+		originalURL string
+		hosts       []string
+		want        bool
+	}{
+		{"https://git.example.com/foo/bar.git", []string{"git.example.com"}, true},
+		{"git@GIT.example.com:foo/bar.git", []string{"git.example.com"}, true},
+		{"https://gitlab.com/foo/bar", []string{"git.example.com"}, false},
+		{"https://gitlab.com/foo/bar", nil, false},
+		{"https://git.example.com/foo/baz", []string{"git.example.com"}, false},
+	}
+	for _, tt := range tests {
+		teaRepo := &gitea.Repository{Mirror: true, OriginalURL: tt.originalURL}
+		if got := repo.CanAdopt(teaRepo, tt.hosts); got != tt.want {
+			t.Errorf("CanAdopt(%q, %v) = %v, want %v", tt.originalURL, tt.hosts, got, tt.want)
+		}
+	}
+
+	if repo.CanAdopt(&gitea.Repository{OriginalURL: "https://git.example.com/foo/bar"}, []string{"git.example.com"}) {
+		t.Error("CanAdopt adopted a repository that is not a mirror")
+	}
+}
+
+func TestIsMyMirrorPort(t *testing.T) {
+	repo := SourceRepository{URLS: []string{"http://git.example.com:3000/owner/repo.git"}}
+	if !repo.IsMyMirror(&gitea.Repository{Mirror: true, OriginalURL: "http://git.example.com:3000/owner/repo"}) {
+		t.Error("IsMyMirror did not match the same port")
+	}
+	if repo.IsMyMirror(&gitea.Repository{Mirror: true, OriginalURL: "http://git.example.com:4000/owner/repo"}) {
+		t.Error("IsMyMirror matched a different port")
+	}
+}
@@ -11,21 +11,45 @@ import (
 const ArchivedMirrorInterval = "0s"
 
 type SyncRepository struct {
-	Topics      []string
-	Description string
-	Private     bool
-	Archived    bool
-	PushedAt    time.Time
+	Topics        []string
+	Description   string
+	Website       string
+	DefaultBranch string
+	Private       bool
+	Archived      bool
+	Empty         *bool // nil when the source can not tell if the repository is empty
+	PushedAt      time.Time
 }
 
 func (sr SyncRepository) StaleMirror(teaRepo *gitea.Repository) bool {
 	return sr.PushedAt.After(teaRepo.MirrorUpdated)
 }
 
+// Broken returns why the mirror teaRepo is broken or an empty string when it is healthy.
+func (sr SyncRepository) Broken(teaRepo *gitea.Repository, staleAfter time.Duration) string {
+	if teaRepo.Empty && sr.Empty != nil && !*sr.Empty {
+		return "mirror is empty"
+	}
+
+	if behind := sr.PushedAt.Sub(teaRepo.MirrorUpdated); staleAfter > 0 && behind > staleAfter {
+		return fmt.Sprintf("mirror is %s behind source", behind.Round(time.Second))
+	}
+
+	return ""
+}
+
 func (sr SyncRepository) DiffDescription(teaRepo *gitea.Repository) bool {
 	return teaRepo.Description != sr.Description
 }
 
+func (sr SyncRepository) DiffWebsite(teaRepo *gitea.Repository) bool {
+	return teaRepo.Website != sr.Website
+}
+
+func (sr SyncRepository) DiffDefaultBranch(teaRepo *gitea.Repository) bool {
+	return sr.DefaultBranch != "" && teaRepo.DefaultBranch != sr.DefaultBranch
+}
+
 func (sr SyncRepository) DiffVisibility(teaRepo *gitea.Repository) bool {
 	return teaRepo.Private != sr.Private
 }
@@ -55,84 +79,134 @@ Loop:
 
 type SyncConfig struct {
 	SyncDescription    bool
+	SyncWebsite        bool
+	SyncDefaultBranch  bool
 	SyncVisibility     bool
 	SyncTopics         bool
 	SyncMirrorInterval bool
+	SyncArchived       bool
 	DestMirrorInterval string
 }
 
 type SyncOutput struct {
 	UpdateDescription    bool
+	UpdateWebsite        bool
+	UpdateDefaultBranch  bool
 	UpdateTopics         bool
 	UpdateVisibility     bool
 	UpdateMirrorInterval bool
+	UpdateArchived       bool
 	SyncMirror           bool
+	SkipSyncMirror       bool // archived repositories can not be mirror synced
 }
 
-func Sync(client *gitea.Client, teaRepo *gitea.Repository, sourceRepo *SyncRepository, config *SyncConfig) (SyncOutput, error) {
+// Sync syncs teaRepo with sourceRepo, forceSyncMirror mirror syncs it even when it is not stale.
+func Sync(client *gitea.Client, teaRepo *gitea.Repository, sourceRepo *SyncRepository, config *SyncConfig, forceSyncMirror bool) (SyncOutput, error) {
 	owner := teaRepo.Owner.UserName
 	repoName := teaRepo.Name
 
 	var output SyncOutput
 	var reterr error
 
-	// Sync Description, MirrorInterval, Visibility
-	{
-		var archivedMirrorInterval = ArchivedMirrorInterval
-		editRepoOption := gitea.EditRepoOption{}
-		shouldEditRepo := false
+	syncMirror := forceSyncMirror || sourceRepo.StaleMirror(teaRepo)
 
-		if config.SyncDescription && sourceRepo.DiffDescription(teaRepo) {
-			editRepoOption.Description = &sourceRepo.Description
+	// Diff Description, Website, DefaultBranch, MirrorInterval, Visibility
+	var archivedMirrorInterval = ArchivedMirrorInterval
+	editRepoOption := gitea.EditRepoOption{}
+	shouldEditRepo := false
 
-			output.UpdateDescription = true
-			shouldEditRepo = true
-		}
+	if config.SyncDescription && sourceRepo.DiffDescription(teaRepo) {
+		editRepoOption.Description = &sourceRepo.Description
 
-		if config.SyncVisibility && sourceRepo.DiffVisibility(teaRepo) {
-			editRepoOption.Private = &sourceRepo.Private
+		output.UpdateDescription = true
+		shouldEditRepo = true
+	}
 
-			output.UpdateVisibility = true
-			shouldEditRepo = true
-		}
+	if config.SyncWebsite && sourceRepo.DiffWebsite(teaRepo) {
+		editRepoOption.Website = &sourceRepo.Website
 
-		if config.SyncMirrorInterval && sourceRepo.DiffMirrorInterval(teaRepo) {
-			if sourceRepo.Archived {
-				editRepoOption.MirrorInterval = &archivedMirrorInterval
-			} else {
-				editRepoOption.MirrorInterval = &config.DestMirrorInterval
-			}
+		output.UpdateWebsite = true
+		shouldEditRepo = true
+	}
+
+	if config.SyncDefaultBranch && sourceRepo.DiffDefaultBranch(teaRepo) {
+		// The default branch can only be changed after the mirror has fetched it
+		if ok, err := HasBranch(client, owner, repoName, sourceRepo.DefaultBranch); err != nil {
+			reterr = errors.Join(reterr, fmt.Errorf("could not get repo branch: %w", err))
+		} else if ok {
+			editRepoOption.DefaultBranch = &sourceRepo.DefaultBranch
 
-			output.UpdateMirrorInterval = true
+			output.UpdateDefaultBranch = true
 			shouldEditRepo = true
+		} else {
+			syncMirror = true
 		}
+	}
 
-		if shouldEditRepo {
-			_, _, err := client.EditRepo(owner, repoName, editRepoOption)
-			if err != nil {
-				reterr = errors.Join(reterr, fmt.Errorf("could not edit repo: %w", err))
-				output.UpdateDescription = false
-				output.UpdateVisibility = false
-				output.UpdateMirrorInterval = false
-			}
+	if config.SyncVisibility && sourceRepo.DiffVisibility(teaRepo) {
+		editRepoOption.Private = &sourceRepo.Private
+
+		output.UpdateVisibility = true
+		shouldEditRepo = true
+	}
+
+	if config.SyncMirrorInterval && sourceRepo.DiffMirrorInterval(teaRepo) {
+		if sourceRepo.Archived {
+			editRepoOption.MirrorInterval = &archivedMirrorInterval
+		} else {
+			editRepoOption.MirrorInterval = &config.DestMirrorInterval
 		}
+
+		output.UpdateMirrorInterval = true
+		shouldEditRepo = true
 	}
 
-	// Sync Topics
+	// Diff Topics
+	shouldSetTopics := false
 	if config.SyncTopics {
 		if teaTopics, _, err := client.ListRepoTopics(owner, repoName, gitea.ListRepoTopicsOptions{}); err != nil {
 			reterr = errors.Join(reterr, fmt.Errorf("could not get repo topics: %w", err))
 		} else if sourceRepo.DiffTopics(teaTopics) {
-			if _, err := client.SetRepoTopics(owner, repoName, sourceRepo.Topics); err != nil {
-				reterr = errors.Join(reterr, fmt.Errorf("could not set repo topics: %w", err))
-			} else {
-				output.UpdateTopics = true
-			}
+			shouldSetTopics = true
+		}
+	}
+
+	// Unarchive first because archived repos reject edits and mirror syncs
+	archived := teaRepo.Archived
+	if config.SyncArchived && archived && (!sourceRepo.Archived || shouldEditRepo || shouldSetTopics || syncMirror) {
+		if err := setArchived(client, owner, repoName, false); err != nil {
+			return SyncOutput{}, errors.Join(reterr, fmt.Errorf("could not unarchive repo: %w", err))
+		}
+		archived = false
+		output.UpdateArchived = true
+	}
+
+	// Sync Description, Website, DefaultBranch, MirrorInterval, Visibility
+	if shouldEditRepo {
+		_, _, err := client.EditRepo(owner, repoName, editRepoOption)
+		if err != nil {
+			reterr = errors.Join(reterr, fmt.Errorf("could not edit repo: %w", err))
+			output.UpdateDescription = false
+			output.UpdateWebsite = false
+			output.UpdateDefaultBranch = false
+			output.UpdateVisibility = false
+			output.UpdateMirrorInterval = false
 		}
 	}
 
-	// Handle cases where the source had commits after it was archived
-	if sourceRepo.StaleMirror(teaRepo) {
+	// Sync Topics
+	if shouldSetTopics {
+		if _, err := client.SetRepoTopics(owner, repoName, sourceRepo.Topics); err != nil {
+			reterr = errors.Join(reterr, fmt.Errorf("could not set repo topics: %w", err))
+		} else {
+			output.UpdateTopics = true
+		}
+	}
+
+	// Handle cases where the source had commits after it was archived, the default branch is missing, or the mirror is forced to sync
+	if syncMirror && archived {
+		output.SkipSyncMirror = true
+	} else if syncMirror {
 		_, err := client.MirrorSync(owner, repoName)
 		if err != nil {
 			reterr = errors.Join(reterr, fmt.Errorf("could not mirror sync: %w", err))
@@ -141,5 +215,19 @@ func Sync(client *gitea.Client, teaRepo *gitea.Repository, sourceRepo *SyncRepos
 		}
 	}
 
+	// Archive last, the mirror sync runs in the background so archiving is left to the next run
+	if config.SyncArchived && sourceRepo.Archived && !archived && !output.SyncMirror {
+		if err := setArchived(client, owner, repoName, true); err != nil {
+			reterr = errors.Join(reterr, fmt.Errorf("could not archive repo: %w", err))
+		} else {
+			output.UpdateArchived = true
+		}
+	}
+
 	return output, reterr
 }
+
+func setArchived(client *gitea.Client, owner, repoName string, archived bool) error {
+	_, _, err := client.EditRepo(owner, repoName, gitea.EditRepoOption{Archived: &archived})
+	return err
+}
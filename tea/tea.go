@@ -1,6 +1,9 @@
 package tea
 
 import (
+	"fmt"
+	"net/url"
+
 	"code.gitea.io/sdk/gitea"
 )
 
@@ -19,16 +22,21 @@ func ConvertList(teaRepos []*gitea.Repository, getTopic func(r *gitea.Repository
 func Convert(r *gitea.Repository, topics []string) SourceRepository {
 	return SourceRepository{
 		SyncRepository: SyncRepository{
-			Topics:      topics,
-			Description: r.Description,
-			Private:     r.Private,
-			Archived:    r.Archived,
-			PushedAt:    r.Updated,
+			Topics:        topics,
+			Description:   r.Description,
+			Website:       r.Website,
+			DefaultBranch: r.DefaultBranch,
+			Private:       r.Private,
+			Archived:      r.Archived,
+			Empty:         gitea.OptionalBool(r.Empty),
+			PushedAt:      r.Updated,
 		},
-		Owner: r.Owner.UserName,
-		Name:  r.Name,
-		Fork:  r.Fork,
-		URLS:  []string{r.CloneURL},
+		Owner:     r.Owner.UserName,
+		Name:      r.Name,
+		Fork:      r.Fork,
+		HTMLURL:   r.HTMLURL,
+		AvatarURL: r.AvatarURL,
+		URLS:      []string{r.CloneURL},
 	}
 }
 
@@ -44,6 +52,76 @@ func GetRepoOrNil(client *gitea.Client, owner, repoName string) (*gitea.Reposito
 	return repo, nil
 }
 
+func ConvertOwner(u *gitea.User) SourceOwner {
+	return SourceOwner{
+		Name:        u.UserName,
+		FullName:    u.FullName,
+		Description: u.Description,
+		Website:     u.Website,
+		AvatarURL:   u.AvatarURL,
+		Visibility:  u.Visibility,
+	}
+}
+
+func GetOwner(client *gitea.Client, name string) (SourceOwner, error) {
+	u, _, err := client.GetUserInfo(name)
+	if err != nil {
+		return SourceOwner{}, err
+	}
+
+	return ConvertOwner(u), nil
+}
+
+// CreateOrgIfNotExist creates the organization name based on sourceOwner when there is no user or organization called name.
+func CreateOrgIfNotExist(client *gitea.Client, destURL, destToken, name string, sourceOwner *SourceOwner) (bool, error) {
+	_, resp, err := client.GetUserInfo(name)
+	if err == nil {
+		return false, nil
+	}
+	if resp == nil || resp.StatusCode != 404 {
+		return false, err
+	}
+
+	opts := gitea.CreateOrgOption{Name: name, Visibility: gitea.VisibleTypePublic}
+	if sourceOwner != nil {
+		opts.FullName = sourceOwner.FullName
+		opts.Description = sourceOwner.Description
+		opts.Website = sourceOwner.Website
+		if sourceOwner.Visibility != "" {
+			opts.Visibility = sourceOwner.Visibility
+		}
+	}
+
+	if _, _, err := client.CreateOrg(opts); err != nil {
+		return false, fmt.Errorf("could not create org: %w", err)
+	}
+
+	if sourceOwner != nil && sourceOwner.AvatarURL != "" {
+		data, err := DownloadAvatar(sourceOwner.AvatarURL)
+		if err != nil {
+			return true, err
+		}
+
+		if err := updateAvatar(destURL, destToken, fmt.Sprintf("/orgs/%s/avatar", url.PathEscape(name)), data); err != nil {
+			return true, fmt.Errorf("could not update org avatar: %w", err)
+		}
+	}
+
+	return true, nil
+}
+
+func HasBranch(client *gitea.Client, owner, repoName, branch string) (bool, error) {
+	_, resp, err := client.GetRepoBranch(owner, repoName, branch)
+	if err != nil {
+		if resp != nil && resp.StatusCode == 404 {
+			return false, nil
+		}
+		return false, err
+	}
+
+	return true, nil
+}
+
 func ListRepos(client *gitea.Client, owner string, skipPrivate bool, skipForks bool) ([]*gitea.Repository, error) {
 	opts := gitea.ListOptions{Page: -1}
 	var repos []*gitea.Repository
@@ -0,0 +1,59 @@
+package tea
+
+import (
+	"net"
+	"net/url"
+	"strings"
+)
+
+var defaultPorts = map[string]string{
+	"http":  "80",
+	"https": "443",
+	"ssh":   "22",
+	"git":   "9418",
+}
+
+// NormalizeURL returns the host and path of a git URL so that different URLs of the same repository are equal.
+// The scheme, credentials, default port, ".git" suffix, and trailing slashes are removed (e.g. "git@GitHub.com:owner/repo.git" becomes "github.com/owner/repo").
+func NormalizeURL(rawURL string) string {
+	rawURL = strings.TrimSpace(rawURL)
+
+	var host, path string
+	if u, err := url.Parse(rawURL); err == nil && u.Host != "" {
+		host, path = u.Hostname(), u.Path
+		if port := u.Port(); port != "" && port != defaultPorts[strings.ToLower(u.Scheme)] {
+			host = net.JoinHostPort(host, port)
+		}
+	} else if before, after, ok := strings.Cut(rawURL, ":"); ok && !strings.Contains(before, "/") {
+		// SCP-like syntax (e.g. "git@github.com:owner/repo.git")
+		_, host, _ = strings.Cut(before, "@")
+		if host == "" {
+			host = before
+		}
+		path = after
+	} else {
+		path = rawURL
+	}
+
+	path = strings.Trim(path, "/")
+	path = strings.TrimSuffix(path, ".git")
+	path = strings.TrimRight(path, "/")
+
+	return strings.ToLower(host + "/" + path)
+}
+
+// URLHost returns the normalized host of a git URL without the port (e.g. "github.com").
+func URLHost(rawURL string) string {
+	host, _, _ := strings.Cut(NormalizeURL(rawURL), "/")
+	if hostname, _, err := net.SplitHostPort(host); err == nil {
+		return hostname
+	}
+
+	return host
+}
+
+// URLPath returns the normalized path of a git URL without the host (e.g. "owner/repo").
+func URLPath(rawURL string) string {
+	_, path, _ := strings.Cut(NormalizeURL(rawURL), "/")
+	return path
+}